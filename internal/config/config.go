@@ -0,0 +1,135 @@
+// Package config loads vibewatch's optional user configuration file.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/theme"
+)
+
+// defaultCheckQuiet is how long changes must stop before checks re-run.
+const defaultCheckQuiet = 2 * time.Second
+
+// Hook is a shell command that runs when a batch of matching changes settles.
+type Hook struct {
+	Name string `json:"name"`
+	// Match is a glob relative to the watched directory (e.g. "pkg/**" or "*.go").
+	// An empty Match runs the hook for every batch.
+	Match string `json:"match"`
+	// Command is run with "sh -c" from the watched directory. The placeholder
+	// {files} is replaced with the matching changed files.
+	Command string `json:"command"`
+}
+
+// Config holds user settings read from the config file.
+type Config struct {
+	Hooks []Hook `json:"hooks"`
+	// Checks are hooks shown in the checks panel. They re-run once changes
+	// have been quiet for CheckQuiet (a Go duration such as "2s").
+	Checks     []Hook `json:"checks"`
+	CheckQuiet string `json:"check_quiet"`
+
+	CheckQuietPeriod time.Duration `json:"-"`
+
+	// EditorURI is the template shown for GUI editors, with {path} and {line}
+	// placeholders (e.g. "vscode://file{path}:{line}").
+	EditorURI string `json:"editor_uri"`
+
+	// FeedbackFile is where review comments are written. The extension picks
+	// the format: .json, .jsonl or Markdown for anything else.
+	FeedbackFile string `json:"feedback_file"`
+
+	// Context is the number of context lines shown around changes.
+	Context int `json:"context"`
+
+	// Keys remaps key bindings by name, e.g. {"next_hunk": ["ctrl+n"]}.
+	Keys map[string][]string `json:"keys"`
+
+	// Theme names the color theme: "auto" (the default, chosen from the
+	// terminal background), a built-in theme or one defined in Themes.
+	Theme  string                 `json:"theme"`
+	Themes map[string]theme.Theme `json:"themes"`
+
+	// Watcher selects how changes are detected: "auto", "fsnotify" or "poll".
+	// PollInterval is how often polling rescans (a Go duration such as "2s").
+	Watcher      string `json:"watcher"`
+	PollInterval string `json:"poll_interval"`
+
+	PollIntervalPeriod time.Duration `json:"-"`
+}
+
+// DefaultPath returns the config file location.
+// On all platforms: $XDG_CONFIG_HOME/vibewatch/config.json, falling back to ~/.config.
+func DefaultPath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configHome, "vibewatch", "config.json")
+}
+
+// Load reads the config file at path. A missing file yields an empty config.
+func Load(path string) (*Config, error) {
+	cfg := &Config{CheckQuietPeriod: defaultCheckQuiet, Context: differ.DefaultContext}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	if err := validateHooks(cfg.Hooks, "hook"); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateHooks(cfg.Checks, "check"); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if cfg.Context < 0 {
+		return nil, fmt.Errorf("%s: context must not be negative", path)
+	}
+
+	cfg.CheckQuietPeriod = defaultCheckQuiet
+	if cfg.CheckQuiet != "" {
+		d, err := time.ParseDuration(cfg.CheckQuiet)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid check_quiet: %w", path, err)
+		}
+		cfg.CheckQuietPeriod = d
+	}
+
+	if cfg.PollInterval != "" {
+		d, err := time.ParseDuration(cfg.PollInterval)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%s: invalid poll_interval %q", path, cfg.PollInterval)
+		}
+		cfg.PollIntervalPeriod = d
+	}
+
+	return cfg, nil
+}
+
+// validateHooks requires a command on every hook and names unnamed ones.
+func validateHooks(hooks []Hook, kind string) error {
+	for i, h := range hooks {
+		if h.Command == "" {
+			return fmt.Errorf("%s %d has no command", kind, i+1)
+		}
+		if h.Name == "" {
+			hooks[i].Name = fmt.Sprintf("%s%d", kind, i+1)
+		}
+	}
+	return nil
+}
@@ -23,12 +23,24 @@ var (
 // Differ computes diffs for changed files.
 type Differ interface {
 	Diff(filePath string) (types.DiffEntry, error)
+	// DiffContext computes an uncached diff with the given number of context lines.
+	DiffContext(filePath string, context int) (types.DiffEntry, error)
 	// DirtyFiles returns DiffEntries for all files with uncommitted changes.
 	DirtyFiles() ([]types.DiffEntry, error)
+	// RepoDirtyFiles is DirtyFiles restricted to the repository with the given name.
+	RepoDirtyFiles(repo string) ([]types.DiffEntry, error)
 	// RepoRoots returns the root paths of all repositories being watched.
 	RepoRoots() []string
 	// RepoRootsWithNames returns a map of repo root paths to repo names.
 	RepoRootsWithNames() map[string]string
+	// SetBase makes diffs compare the working tree against a git ref instead
+	// of the index; "" restores the index.
+	SetBase(ref string) error
+	// Base returns the ref set with SetBase, or "" when diffing the index.
+	Base() string
+	// IgnorePath leaves a file out of DirtyFiles, such as one vibewatch
+	// writes itself.
+	IgnorePath(path string)
 }
 
 // cacheEntry represents a cached diff result
@@ -39,9 +51,15 @@ type cacheEntry struct {
 	isNew     bool
 }
 
+// DefaultContext is the number of context lines shown around changes.
+const DefaultContext = 3
+
 // GitDiffer uses git to compute diffs.
 type GitDiffer struct {
 	root       string
+	context    int
+	base       string
+	ignored    map[string]bool
 	diffCache  map[string]cacheEntry
 	cacheMutex sync.Mutex
 }
@@ -66,23 +84,57 @@ func NewGit(root string) (*GitDiffer, error) {
 	}
 	return &GitDiffer{
 		root:      root,
+		context:   DefaultContext,
+		ignored:   make(map[string]bool),
 		diffCache: make(map[string]cacheEntry),
 	}, nil
 }
 
-// Diff computes the diff for a single file.
-func (g *GitDiffer) Diff(filePath string) (types.DiffEntry, error) {
-	if filePath == "__GIT_OPERATION__" {
-		return types.DiffEntry{
-			FilePath:  filePath,
-			Timestamp: time.Now(),
-			Repo:      filepath.Base(g.root),
-			Diff:      "",
-			Error:     "",
-			IsNew:     false,
-		}, nil
+// IgnorePath leaves path out of DirtyFiles. Call it before diffing starts.
+func (g *GitDiffer) IgnorePath(path string) {
+	g.ignored[path] = true
+}
+
+// SetContext sets the number of context lines used by Diff and DirtyFiles.
+func (g *GitDiffer) SetContext(lines int) {
+	g.cacheMutex.Lock()
+	g.context = lines
+	g.diffCache = make(map[string]cacheEntry)
+	g.cacheMutex.Unlock()
+}
+
+// SetBase makes Diff and DirtyFiles compare the working tree against ref (a
+// branch, tag or expression such as HEAD~3) instead of the index. The ref is
+// resolved on every diff, so a moving ref follows new commits. An empty ref
+// restores the index diff.
+func (g *GitDiffer) SetBase(ref string) error {
+	if ref != "" && !g.hasCommit(ref) {
+		return fmt.Errorf("%s: unknown revision %q", filepath.Base(g.root), ref)
 	}
+	g.cacheMutex.Lock()
+	g.base = ref
+	g.diffCache = make(map[string]cacheEntry)
+	g.cacheMutex.Unlock()
+	return nil
+}
+
+// Base returns the ref diffs are taken against, or "" for the index.
+func (g *GitDiffer) Base() string {
+	g.cacheMutex.Lock()
+	defer g.cacheMutex.Unlock()
+	return g.base
+}
+
+// hasCommit reports whether ref names a commit in this repository.
+func (g *GitDiffer) hasCommit(ref string) bool {
+	cmd := exec.Command("git", "-C", g.root, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	cmd.Stdout = &bytes.Buffer{}
+	cmd.Stderr = &bytes.Buffer{}
+	return cmd.Run() == nil
+}
 
+// Diff computes the diff for a single file.
+func (g *GitDiffer) Diff(filePath string) (types.DiffEntry, error) {
 	entry := types.DiffEntry{
 		FilePath:  filePath,
 		Timestamp: time.Now(),
@@ -90,6 +142,7 @@ func (g *GitDiffer) Diff(filePath string) (types.DiffEntry, error) {
 	}
 
 	g.cacheMutex.Lock()
+	context := g.context
 	if cached, exists := g.diffCache[filePath]; exists {
 		if time.Since(cached.timestamp) < 1*time.Second {
 			entry.Diff = cached.diff
@@ -101,35 +154,56 @@ func (g *GitDiffer) Diff(filePath string) (types.DiffEntry, error) {
 	}
 	g.cacheMutex.Unlock()
 
-	rel, err := filepath.Rel(g.root, filePath)
-	if err != nil {
-		rel = filePath
+	g.computeDiff(&entry, context)
+	g.cacheResult(filePath, entry)
+	return entry, nil
+}
+
+// DiffContext computes the diff for a single file with the given number of
+// context lines, bypassing the cache.
+func (g *GitDiffer) DiffContext(filePath string, context int) (types.DiffEntry, error) {
+	entry := types.DiffEntry{
+		FilePath:  filePath,
+		Timestamp: time.Now(),
+		Repo:      filepath.Base(g.root),
 	}
+	g.computeDiff(&entry, context)
+	return entry, nil
+}
 
-	diff, err := g.gitDiff(rel)
+// computeDiff fills in the entry's diff against the base ref or the index,
+// falling back to the staged diff and then to the whole file for untracked
+// files.
+func (g *GitDiffer) computeDiff(entry *types.DiffEntry, context int) {
+	filePath := entry.FilePath
+	root, rel := g.locate(filePath)
+	base := g.Base()
+	if root != g.root {
+		// Submodules have their own history, so the base ref means nothing there
+		base = ""
+	}
+
+	diff, err := g.gitDiff(root, rel, base, context)
 	if err != nil {
 		entry.Error = err.Error()
-		g.cacheResult(filePath, entry)
-		return entry, nil
+		return
 	}
 
-	if diff == "" {
-		diff, err = g.gitDiffStaged(rel)
+	if diff == "" && base == "" {
+		diff, err = g.gitDiffStaged(root, rel, context)
 		if err != nil {
 			entry.Error = err.Error()
-			g.cacheResult(filePath, entry)
-			return entry, nil
+			return
 		}
 	}
 
 	if diff == "" {
-		tracked, _ := g.isTracked(rel)
+		tracked, _ := g.isTracked(root, rel)
 		if !tracked {
 			diff, err = g.gitDiffUntracked(filePath)
 			if err != nil {
 				entry.Error = err.Error()
-				g.cacheResult(filePath, entry)
-				return entry, nil
+				return
 			}
 			entry.IsNew = true
 		} else {
@@ -137,37 +211,67 @@ func (g *GitDiffer) Diff(filePath string) (types.DiffEntry, error) {
 			entry.Diff = ""
 			entry.Error = ""
 			entry.IsNew = false
-			g.cacheResult(filePath, entry)
-			return entry, nil
+			return
 		}
 	}
 
 	entry.Diff = diff
-	g.cacheResult(filePath, entry)
-	return entry, nil
 }
 
-// DirtyFiles returns DiffEntries for all files with uncommitted changes in this repo.
+// DirtyFiles returns DiffEntries for all files with uncommitted changes in this
+// repo and its checked-out submodules, or for all files that differ from the
+// base ref when one is set.
 func (g *GitDiffer) DirtyFiles() ([]types.DiffEntry, error) {
-	cmd := exec.Command("git", "-C", g.root, "diff", "--name-only", "HEAD")
+	relPaths := changedPaths(g.root, g.Base())
+
+	var entries []types.DiffEntry
+	for _, relPath := range relPaths {
+		lower := strings.ToLower(relPath)
+		if strings.HasSuffix(lower, ".tmp") || strings.HasSuffix(lower, ".log") ||
+			strings.HasSuffix(lower, ".bak") || strings.HasSuffix(lower, ".swp") {
+			continue
+		}
+		absPath := filepath.Join(g.root, relPath)
+		if g.ignored[absPath] {
+			continue
+		}
+		entry, _ := g.Diff(absPath)
+		if entry.Diff != "" || entry.IsNew {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// changedPaths lists the files of the repository at root that differ from
+// base (HEAD when "") or from the index, or are untracked, relative to root.
+// It descends into checked-out submodules, whose changes the parent only
+// reports as the submodule path; they are always diffed against their own
+// HEAD.
+func changedPaths(root, base string) []string {
+	changedSince := "HEAD"
+	if base != "" {
+		changedSince = base
+	}
+	cmd := exec.Command("git", "-C", root, "diff", "--name-only", changedSince)
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = &bytes.Buffer{}
 	if err := cmd.Run(); err != nil {
-		cmd = exec.Command("git", "-C", g.root, "diff", "--name-only")
+		cmd = exec.Command("git", "-C", root, "diff", "--name-only")
 		out.Reset()
 		cmd.Stdout = &out
 		cmd.Stderr = &bytes.Buffer{}
 		cmd.Run()
 	}
 
-	cmd2 := exec.Command("git", "-C", g.root, "diff", "--name-only")
+	cmd2 := exec.Command("git", "-C", root, "diff", "--name-only")
 	var out2 bytes.Buffer
 	cmd2.Stdout = &out2
 	cmd2.Stderr = &bytes.Buffer{}
 	cmd2.Run()
 
-	cmd3 := exec.Command("git", "-C", g.root, "ls-files", "--others", "--exclude-standard")
+	cmd3 := exec.Command("git", "-C", root, "ls-files", "--others", "--exclude-standard")
 	var out3 bytes.Buffer
 	cmd3.Stdout = &out3
 	cmd3.Stderr = &bytes.Buffer{}
@@ -186,24 +290,70 @@ func (g *GitDiffer) DirtyFiles() ([]types.DiffEntry, error) {
 		}
 	}
 
-	var entries []types.DiffEntry
-	for _, relPath := range relPaths {
-		lower := strings.ToLower(relPath)
-		if strings.HasSuffix(lower, ".tmp") || strings.HasSuffix(lower, ".log") ||
-			strings.HasSuffix(lower, ".bak") || strings.HasSuffix(lower, ".swp") {
+	for _, sub := range submodules(root) {
+		for _, rel := range changedPaths(filepath.Join(root, sub), "") {
+			relPaths = append(relPaths, filepath.Join(sub, rel))
+		}
+	}
+	return relPaths
+}
+
+// submodules returns the paths, relative to root, of the repository's
+// submodules that are checked out.
+func submodules(root string) []string {
+	cmd := exec.Command("git", "-C", root, "ls-files", "--stage")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &bytes.Buffer{}
+	if err := cmd.Run(); err != nil {
+		return nil
+	}
+	var subs []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		// "160000 <hash> <stage>\t<path>" is a gitlink
+		meta, path, ok := strings.Cut(line, "\t")
+		if !ok || !strings.HasPrefix(meta, "160000 ") {
 			continue
 		}
-		absPath := filepath.Join(g.root, relPath)
-		entry, _ := g.Diff(absPath)
-		if entry.Diff != "" || entry.IsNew {
-			entries = append(entries, entry)
+		if _, err := os.Lstat(filepath.Join(root, path, ".git")); err == nil {
+			subs = append(subs, path)
 		}
 	}
-	return entries, nil
+	return subs
+}
+
+// RepoDirtyFiles returns the dirty files of this repo if it is the named one.
+func (g *GitDiffer) RepoDirtyFiles(repo string) ([]types.DiffEntry, error) {
+	if repo != filepath.Base(g.root) {
+		return nil, fmt.Errorf("unknown repository %q", repo)
+	}
+	return g.DirtyFiles()
+}
+
+// locate returns the repository a file is diffed in and its path there: the
+// innermost submodule (or nested repository) containing it, or g.root.
+func (g *GitDiffer) locate(filePath string) (string, string) {
+	for dir := filepath.Dir(filePath); strings.HasPrefix(dir, g.root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			rel, _ := filepath.Rel(dir, filePath)
+			return dir, rel
+		}
+	}
+	rel, err := filepath.Rel(g.root, filePath)
+	if err != nil {
+		rel = filePath
+	}
+	return g.root, rel
 }
 
-func (g *GitDiffer) gitDiff(relPath string) (string, error) {
-	cmd := exec.Command("git", "-C", g.root, "diff", "--no-color", "--unified=3", "--", relPath)
+// gitDiff diffs the working tree against base, or against the index when
+// base is "".
+func (g *GitDiffer) gitDiff(root, relPath, base string, context int) (string, error) {
+	args := []string{"-C", root, "diff", "--no-color", fmt.Sprintf("--unified=%d", context)}
+	if base != "" {
+		args = append(args, base)
+	}
+	cmd := exec.Command("git", append(args, "--", relPath)...)
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = &bytes.Buffer{}
@@ -213,8 +363,8 @@ func (g *GitDiffer) gitDiff(relPath string) (string, error) {
 	return strings.TrimSpace(out.String()), nil
 }
 
-func (g *GitDiffer) gitDiffStaged(relPath string) (string, error) {
-	cmd := exec.Command("git", "-C", g.root, "diff", "--no-color", "--unified=3", "--cached", "--", relPath)
+func (g *GitDiffer) gitDiffStaged(root, relPath string, context int) (string, error) {
+	cmd := exec.Command("git", "-C", root, "diff", "--no-color", fmt.Sprintf("--unified=%d", context), "--cached", "--", relPath)
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = &bytes.Buffer{}
@@ -224,8 +374,8 @@ func (g *GitDiffer) gitDiffStaged(relPath string) (string, error) {
 	return strings.TrimSpace(out.String()), nil
 }
 
-func (g *GitDiffer) isTracked(relPath string) (bool, error) {
-	cmd := exec.Command("git", "-C", g.root, "ls-files", "--error-unmatch", relPath)
+func (g *GitDiffer) isTracked(root, relPath string) (bool, error) {
+	cmd := exec.Command("git", "-C", root, "ls-files", "--error-unmatch", relPath)
 	cmd.Stdout = &bytes.Buffer{}
 	cmd.Stderr = &bytes.Buffer{}
 	err := cmd.Run()
@@ -277,7 +427,11 @@ func (g *GitDiffer) RepoRootsWithNames() map[string]string {
 // MultiDiffer routes file paths to the correct GitDiffer based on which repo
 // the file belongs to. Used when watching a parent directory containing multiple repos.
 type MultiDiffer struct {
-	repos []repoEntry // sorted longest-path-first for correct matching
+	mu      sync.RWMutex
+	repos   []repoEntry // sorted longest-path-first for correct matching
+	context int
+	base    string
+	ignored []string
 }
 
 type repoEntry struct {
@@ -305,17 +459,65 @@ func NewMulti(repos map[string]string) (*MultiDiffer, error) {
 		return nil, fmt.Errorf("no valid git repositories found")
 	}
 
-	// Sort longest path first so nested repos match before parents
+	sortRepos(entries)
+	return &MultiDiffer{repos: entries, context: DefaultContext}, nil
+}
+
+// sortRepos sorts longest path first so nested repos match before parents.
+func sortRepos(entries []repoEntry) {
 	sort.Slice(entries, func(i, j int) bool {
 		return len(entries[i].root) > len(entries[j].root)
 	})
+}
 
-	return &MultiDiffer{repos: entries}, nil
+// AddRepo starts diffing a repository found after startup.
+func (m *MultiDiffer) AddRepo(root, name string) error {
+	d, err := NewGit(root)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, repo := range m.repos {
+		if repo.root == root {
+			return nil
+		}
+	}
+	d.SetContext(m.context)
+	d.SetBase(m.base) // repos without the ref keep diffing against the index
+	for _, path := range m.ignored {
+		d.IgnorePath(path)
+	}
+	m.repos = append(m.repos, repoEntry{root: root, name: name, differ: d})
+	sortRepos(m.repos)
+	return nil
+}
+
+// RemoveRepo stops diffing a repository that was deleted. It returns the
+// repo's name, or "" if root was not known.
+func (m *MultiDiffer) RemoveRepo(root string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, repo := range m.repos {
+		if repo.root == root {
+			m.repos = append(m.repos[:i], m.repos[i+1:]...)
+			return repo.name
+		}
+	}
+	return ""
+}
+
+// snapshot returns the current repos, safe to range over while repos are
+// added or removed.
+func (m *MultiDiffer) snapshot() []repoEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]repoEntry(nil), m.repos...)
 }
 
 // Diff finds the matching repo for the file path and computes the diff.
 func (m *MultiDiffer) Diff(filePath string) (types.DiffEntry, error) {
-	for _, repo := range m.repos {
+	for _, repo := range m.snapshot() {
 		if strings.HasPrefix(filePath, repo.root+string(filepath.Separator)) || filePath == repo.root {
 			entry, err := repo.differ.Diff(filePath)
 			entry.Repo = repo.name
@@ -329,10 +531,86 @@ func (m *MultiDiffer) Diff(filePath string) (types.DiffEntry, error) {
 	}, nil
 }
 
+// DiffContext finds the matching repo for the file path and computes an
+// uncached diff with the given number of context lines.
+func (m *MultiDiffer) DiffContext(filePath string, context int) (types.DiffEntry, error) {
+	for _, repo := range m.snapshot() {
+		if strings.HasPrefix(filePath, repo.root+string(filepath.Separator)) || filePath == repo.root {
+			entry, err := repo.differ.DiffContext(filePath, context)
+			entry.Repo = repo.name
+			return entry, err
+		}
+	}
+	return types.DiffEntry{
+		FilePath:  filePath,
+		Timestamp: time.Now(),
+		Error:     "file not inside any known git repository",
+	}, nil
+}
+
+// SetContext sets the number of context lines for every repo.
+func (m *MultiDiffer) SetContext(lines int) {
+	m.mu.Lock()
+	m.context = lines
+	m.mu.Unlock()
+	for _, repo := range m.snapshot() {
+		repo.differ.SetContext(lines)
+	}
+}
+
+// IgnorePath leaves path out of DirtyFiles in every repo, including repos
+// added later.
+func (m *MultiDiffer) IgnorePath(path string) {
+	m.mu.Lock()
+	m.ignored = append(m.ignored, path)
+	m.mu.Unlock()
+	for _, repo := range m.snapshot() {
+		repo.differ.IgnorePath(path)
+	}
+}
+
+// SetBase sets the ref every repo is diffed against. Repos where ref does not
+// resolve keep diffing against the index, and are named in the returned
+// error; the base is still applied to the others. When ref resolves in no
+// repo, nothing changes.
+func (m *MultiDiffer) SetBase(ref string) error {
+	repos := m.snapshot()
+	var missing []string
+	for _, repo := range repos {
+		if ref != "" && !repo.differ.hasCommit(ref) {
+			missing = append(missing, repo.name)
+		}
+	}
+	if len(missing) > 0 && len(missing) == len(repos) {
+		return fmt.Errorf("unknown revision %q", ref)
+	}
+
+	m.mu.Lock()
+	m.base = ref
+	m.mu.Unlock()
+	for _, repo := range repos {
+		if err := repo.differ.SetBase(ref); err != nil {
+			repo.differ.SetBase("")
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("unknown revision %q in %s, diffing against the index there", ref, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Base returns the ref set with SetBase, or "" for the index.
+func (m *MultiDiffer) Base() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.base
+}
+
 // DirtyFiles returns DiffEntries for all dirty files across all repos.
 func (m *MultiDiffer) DirtyFiles() ([]types.DiffEntry, error) {
 	var all []types.DiffEntry
-	for _, repo := range m.repos {
+	for _, repo := range m.snapshot() {
 		entries, err := repo.differ.DirtyFiles()
 		if err != nil {
 			continue
@@ -345,10 +623,26 @@ func (m *MultiDiffer) DirtyFiles() ([]types.DiffEntry, error) {
 	return all, nil
 }
 
+// RepoDirtyFiles returns DiffEntries for the dirty files of the named repo.
+func (m *MultiDiffer) RepoDirtyFiles(repo string) ([]types.DiffEntry, error) {
+	for _, r := range m.snapshot() {
+		if r.name != repo {
+			continue
+		}
+		entries, err := r.differ.DirtyFiles()
+		for i := range entries {
+			entries[i].Repo = r.name
+		}
+		return entries, err
+	}
+	return nil, fmt.Errorf("unknown repository %q", repo)
+}
+
 // RepoRoots returns the root paths of all discovered repos.
 func (m *MultiDiffer) RepoRoots() []string {
-	roots := make([]string, len(m.repos))
-	for i, r := range m.repos {
+	repos := m.snapshot()
+	roots := make([]string, len(repos))
+	for i, r := range repos {
 		roots[i] = r.root
 	}
 	return roots
@@ -357,7 +651,7 @@ func (m *MultiDiffer) RepoRoots() []string {
 // RepoRootsWithNames returns a map of repo root paths to repo names.
 func (m *MultiDiffer) RepoRootsWithNames() map[string]string {
 	repos := make(map[string]string)
-	for _, r := range m.repos {
+	for _, r := range m.snapshot() {
 		repos[r.root] = r.name
 	}
 	return repos
@@ -380,7 +674,9 @@ func DiscoverRepos(parentDir string) (map[string]string, error) {
 		if d.Name() == ".git" {
 			return filepath.SkipDir
 		}
-		// Check if this directory contains a .git folder (i.e. is a repo root)
+		// Check if this directory contains .git (i.e. is a repo root). It is
+		// a directory, or for worktrees and submodules a file pointing to the
+		// real git directory
 		gitDir := filepath.Join(path, ".git")
 		if _, statErr := os.Stat(gitDir); statErr == nil {
 			repos[path] = filepath.Base(path)
@@ -404,6 +700,63 @@ func GetBranch(repoRoot string) string {
 	return strings.TrimSpace(out.String())
 }
 
+// ShowCommit returns a timeline entry for a commit: its subject, author,
+// files and the diff it introduced.
+func ShowCommit(repoRoot, hash string) (types.DiffEntry, error) {
+	entry := types.DiffEntry{FilePath: hash, Timestamp: time.Now()}
+	git := func(args ...string) (string, error) {
+		cmd := exec.Command("git", append([]string{"-C", repoRoot}, args...)...)
+		var out, stderr bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return out.String(), nil
+	}
+
+	info, err := git("show", "-s", "--format=%H%x00%an%x00%s", hash)
+	if err != nil {
+		return entry, err
+	}
+	fields := strings.SplitN(strings.TrimSpace(info), "\x00", 3)
+	if len(fields) != 3 {
+		return entry, fmt.Errorf("unexpected git show output for %s", hash)
+	}
+	commit := &types.Commit{Hash: fields[0], Author: fields[1], Message: fields[2]}
+	entry.FilePath = commit.Hash
+	entry.Commit = commit
+
+	names, err := git("show", "--format=", "--name-only", commit.Hash)
+	if err != nil {
+		return entry, err
+	}
+	for _, name := range strings.Split(strings.TrimSpace(names), "\n") {
+		if name != "" {
+			commit.Files = append(commit.Files, name)
+		}
+	}
+
+	diff, err := git("show", "--format=", "--no-color", "--no-ext-diff", commit.Hash)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry, nil
+	}
+	entry.Diff = strings.TrimSpace(diff)
+	return entry, nil
+}
+
+// GitDir returns the absolute git directory of the repository containing dir,
+// or "" if there is none. For a worktree it is the worktree's own directory
+// under the main repository's .git.
+func GitDir(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
 // IsGitRepo checks whether a directory is itself a git repository.
 func IsGitRepo(dir string) bool {
 	cmd := exec.Command("git", "-C", dir, "rev-parse", "--git-dir")
@@ -432,3 +785,43 @@ func FindRepoRoot(filePath string, repoRoots []string) string {
 	}
 	return ""
 }
+
+// DiffContents computes a unified diff between two observed states of a file,
+// labelled with name, with the given number of context lines. It returns an
+// empty string when the contents are equal.
+func DiffContents(name string, old, new []byte, context int) (string, error) {
+	if bytes.Equal(old, new) {
+		return "", nil
+	}
+
+	dir, err := os.MkdirTemp("", "vibewatch-diff-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	oldPath := filepath.Join(dir, "old")
+	newPath := filepath.Join(dir, "new")
+	if err := os.WriteFile(oldPath, old, 0o600); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(newPath, new, 0o600); err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("git", "diff", "--no-color", "--no-index", fmt.Sprintf("--unified=%d", context), "--", oldPath, newPath)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &bytes.Buffer{}
+	// git diff --no-index exits with 1 when the files differ
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return "", err
+		}
+	}
+
+	diff := strings.TrimSpace(out.String())
+	diff = strings.ReplaceAll(diff, "a"+oldPath, "a/"+name)
+	diff = strings.ReplaceAll(diff, "b"+newPath, "b/"+name)
+	return diff, nil
+}
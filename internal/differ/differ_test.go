@@ -0,0 +1,143 @@
+package differ
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+)
+
+func TestSetBase(t *testing.T) {
+	root := t.TempDir()
+	initRepo(t, root)
+	writeFile(t, filepath.Join(root, "a.go"), "package a\n")
+	runGit(t, root, "add", "a.go")
+	runGit(t, root, "commit", "--quiet", "-m", "Add a")
+	writeFile(t, filepath.Join(root, "README"), "changed\n")
+	writeFile(t, filepath.Join(root, "s.go"), "package s\n")
+	runGit(t, root, "add", "s.go")
+
+	g, err := NewGit(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each step starts from the state the previous one left
+	steps := []struct {
+		ref     string
+		wantErr bool
+		base    string
+		files   []string
+	}{
+		{"", false, "", []string{"README", "s.go"}},
+		{"HEAD~1", false, "HEAD~1", []string{"README", "a.go", "s.go"}},
+		{"no-such-ref", true, "HEAD~1", []string{"README", "a.go", "s.go"}},
+		{"", false, "", []string{"README", "s.go"}},
+	}
+	for _, step := range steps {
+		err := g.SetBase(step.ref)
+		if (err != nil) != step.wantErr {
+			t.Errorf("SetBase(%q) error = %v, want error: %v", step.ref, err, step.wantErr)
+		}
+		if got := g.Base(); got != step.base {
+			t.Errorf("after SetBase(%q), Base() = %q, want %q", step.ref, got, step.base)
+		}
+		entries, err := g.DirtyFiles()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := relPaths(root, entries); !reflect.DeepEqual(got, step.files) {
+			t.Errorf("after SetBase(%q), DirtyFiles() = %q, want %q", step.ref, got, step.files)
+		}
+	}
+
+	g.SetBase("HEAD~1")
+	entry, _ := g.Diff(filepath.Join(root, "a.go"))
+	if !strings.Contains(entry.Diff, "+package a") {
+		t.Errorf("diff of a committed file against HEAD~1 = %q", entry.Diff)
+	}
+}
+
+func TestMultiSetBase(t *testing.T) {
+	parent := t.TempDir()
+	tagged, untagged := filepath.Join(parent, "tagged"), filepath.Join(parent, "untagged")
+	initRepo(t, tagged)
+	initRepo(t, untagged)
+	runGit(t, tagged, "tag", "v1")
+
+	m, err := NewMulti(map[string]string{tagged: "tagged", untagged: "untagged"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []struct {
+		ref     string
+		errText string
+		base    string
+		bases   map[string]string
+	}{
+		{"v1", `unknown revision "v1" in untagged`, "v1", map[string]string{"tagged": "v1", "untagged": ""}},
+		{"v2", `unknown revision "v2"`, "v1", map[string]string{"tagged": "v1", "untagged": ""}},
+		{"", "", "", map[string]string{"tagged": "", "untagged": ""}},
+	}
+	for _, step := range steps {
+		err := m.SetBase(step.ref)
+		if step.errText == "" && err != nil || step.errText != "" && (err == nil || !strings.Contains(err.Error(), step.errText)) {
+			t.Errorf("SetBase(%q) error = %v, want %q", step.ref, err, step.errText)
+		}
+		if got := m.Base(); got != step.base {
+			t.Errorf("after SetBase(%q), Base() = %q, want %q", step.ref, got, step.base)
+		}
+		bases := make(map[string]string)
+		for _, r := range m.snapshot() {
+			bases[r.name] = r.differ.Base()
+		}
+		if !reflect.DeepEqual(bases, step.bases) {
+			t.Errorf("after SetBase(%q), repo bases = %v, want %v", step.ref, bases, step.bases)
+		}
+	}
+}
+
+func initRepo(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "init", "--quiet")
+	writeFile(t, filepath.Join(dir, "README"), "readme\n")
+	runGit(t, dir, "add", "README")
+	runGit(t, dir, "commit", "--quiet", "-m", "Initial commit")
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// relPaths returns the entries' paths relative to root, sorted.
+func relPaths(root string, entries []types.DiffEntry) []string {
+	var paths []string
+	for _, e := range entries {
+		rel, _ := filepath.Rel(root, e.FilePath)
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,165 @@
+package differ
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hunk is a single "@@ ... @@" section of a unified diff.
+type Hunk struct {
+	Header   string
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []string // body lines, each prefixed with ' ', '+' or '-'
+}
+
+// ParseHunks splits a unified diff into its hunks. File headers are skipped,
+// including those between the files of a multi-file diff such as a commit's.
+func ParseHunks(diff string) []Hunk {
+	var hunks []Hunk
+	var current *Hunk
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git") {
+			// The next file's header ends the hunk
+			current = nil
+			continue
+		}
+		if strings.HasPrefix(line, "@@") {
+			h := Hunk{Header: line, OldLines: 1, NewLines: 1}
+			parseHunkHeader(line, &h)
+			hunks = append(hunks, h)
+			current = &hunks[len(hunks)-1]
+			continue
+		}
+		if current == nil || strings.HasPrefix(line, `\`) {
+			continue
+		}
+		current.Lines = append(current.Lines, line)
+	}
+	return hunks
+}
+
+// parseHunkHeader reads the ranges from "@@ -a,b +c,d @@". Omitted counts default to 1.
+func parseHunkHeader(header string, h *Hunk) {
+	var ranges string
+	if end := strings.Index(header[2:], "@@"); end >= 0 {
+		ranges = strings.TrimSpace(header[2 : end+2])
+	}
+	for _, r := range strings.Fields(ranges) {
+		var start, count int
+		count = 1
+		if strings.Contains(r, ",") {
+			fmt.Sscanf(r[1:], "%d,%d", &start, &count)
+		} else {
+			fmt.Sscanf(r[1:], "%d", &start)
+		}
+		switch r[0] {
+		case '-':
+			h.OldStart, h.OldLines = start, count
+		case '+':
+			h.NewStart, h.NewLines = start, count
+		}
+	}
+}
+
+// FirstChangedLine returns the new-file line number of the first added line,
+// or the hunk's position in the new file when it only removes lines.
+func (h Hunk) FirstChangedLine() int {
+	first, _ := h.ChangedRange()
+	return first
+}
+
+// ChangedRange returns the first and last new-file line numbers touched by the
+// hunk's changes. Pure removals collapse to the line they were removed before.
+func (h Hunk) ChangedRange() (int, int) {
+	line := h.NewStart
+	first, last := 0, 0
+	for _, l := range h.Lines {
+		switch {
+		case strings.HasPrefix(l, "+"):
+			if first == 0 {
+				first = line
+			}
+			last = line
+			line++
+		case strings.HasPrefix(l, "-"):
+			if first == 0 {
+				first = line
+			}
+			if last < line {
+				last = line
+			}
+		default:
+			line++
+		}
+	}
+	if first == 0 {
+		first = h.NewStart
+	}
+	if first < 1 {
+		first = 1
+	}
+	if last < first {
+		last = first
+	}
+	return first, last
+}
+
+// LineAt returns the body lines that show new-file line n: the line itself,
+// preceded by any removed lines directly before it. It reports false when n
+// lies outside the hunk.
+func (h Hunk) LineAt(n int) ([]string, bool) {
+	line := h.NewStart
+	var removed []string
+	for _, l := range h.Lines {
+		if strings.HasPrefix(l, "-") {
+			removed = append(removed, l)
+			continue
+		}
+		if line == n {
+			return append(removed, l), true
+		}
+		removed = nil
+		line++
+	}
+	if line == n && len(removed) > 0 {
+		return removed, true
+	}
+	return nil, false
+}
+
+// HeaderLines marks which lines of a unified diff are file headers: those
+// from the start or a "diff --git" line up to the next "@@". Inside a hunk, a
+// removed "-- comment" reads "--- comment" and is content, not a header.
+func HeaderLines(lines []string) []bool {
+	header := make([]bool, len(lines))
+	inHeader := true
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git"):
+			inHeader = true
+		case strings.HasPrefix(line, "@@"):
+			inHeader = false
+		}
+		header[i] = inHeader
+	}
+	return header
+}
+
+// CountChanges returns the number of added and removed lines in a unified diff.
+func CountChanges(diff string) (added, removed int) {
+	lines := strings.Split(diff, "\n")
+	header := HeaderLines(lines)
+	for i, line := range lines {
+		switch {
+		case header[i]:
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
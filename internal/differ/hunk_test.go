@@ -0,0 +1,168 @@
+package differ
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// commitDiff is a two-file diff like "git show" prints. Its first hunk
+// removes a SQL comment, whose body line starts with "---".
+var commitDiff = strings.Join([]string{
+	"diff --git a/schema.sql b/schema.sql",
+	"index 1111111..2222222 100644",
+	"--- a/schema.sql",
+	"+++ b/schema.sql",
+	"@@ -1,3 +1,3 @@",
+	"--- drop later",
+	"+-- keep",
+	" CREATE TABLE t;",
+	" CREATE INDEX i;",
+	"diff --git a/main.go b/main.go",
+	"index 3333333..4444444 100644",
+	"--- a/main.go",
+	"+++ b/main.go",
+	"@@ -10,2 +10,3 @@ func main() {",
+	" \tx := 1",
+	"+\ty := 2",
+	" \treturn",
+	"@@ -20 +21 @@",
+	"-}",
+	"+} // end",
+	`\ No newline at end of file`,
+}, "\n")
+
+func TestParseHunks(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want []Hunk
+	}{
+		{
+			name: "multiple files",
+			diff: commitDiff,
+			want: []Hunk{
+				{
+					Header: "@@ -1,3 +1,3 @@", OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3,
+					Lines: []string{"--- drop later", "+-- keep", " CREATE TABLE t;", " CREATE INDEX i;"},
+				},
+				{
+					Header: "@@ -10,2 +10,3 @@ func main() {", OldStart: 10, OldLines: 2, NewStart: 10, NewLines: 3,
+					Lines: []string{" \tx := 1", "+\ty := 2", " \treturn"},
+				},
+				{
+					Header: "@@ -20 +21 @@", OldStart: 20, OldLines: 1, NewStart: 21, NewLines: 1,
+					Lines: []string{"-}", "+} // end"},
+				},
+			},
+		},
+		{
+			name: "new file",
+			diff: "--- /dev/null\n+++ b/a.txt\n@@ -0,0 +1,2 @@\n+a\n+b",
+			want: []Hunk{
+				{Header: "@@ -0,0 +1,2 @@", OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 2, Lines: []string{"+a", "+b"}},
+			},
+		},
+		{
+			name: "no hunks",
+			diff: "diff --git a/bin b/bin\nBinary files differ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseHunks(tt.diff); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseHunks() =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		hunk        Hunk
+		first, last int
+	}{
+		{
+			name:  "addition",
+			hunk:  Hunk{NewStart: 10, Lines: []string{" a", "+b", "+c", " d"}},
+			first: 11, last: 12,
+		},
+		{
+			name:  "replacement",
+			hunk:  Hunk{NewStart: 5, Lines: []string{"-a", "-b", "+c", " d"}},
+			first: 5, last: 5,
+		},
+		{
+			name:  "removal",
+			hunk:  Hunk{NewStart: 7, Lines: []string{" a", "-b", " c"}},
+			first: 8, last: 8,
+		},
+		{
+			name:  "removal at the top of the file",
+			hunk:  Hunk{NewStart: 0, Lines: []string{"-a"}},
+			first: 1, last: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, last := tt.hunk.ChangedRange()
+			if first != tt.first || last != tt.last {
+				t.Errorf("ChangedRange() = %d, %d, want %d, %d", first, last, tt.first, tt.last)
+			}
+		})
+	}
+}
+
+func TestLineAt(t *testing.T) {
+	h := Hunk{NewStart: 10, Lines: []string{" a", "-b", "+c", " d", "-e"}}
+	tests := []struct {
+		n    int
+		want []string
+		ok   bool
+	}{
+		{9, nil, false},
+		{10, []string{" a"}, true},
+		{11, []string{"-b", "+c"}, true},
+		{12, []string{" d"}, true},
+		{13, []string{"-e"}, true},
+		{14, nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := h.LineAt(tt.n)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LineAt(%d) = %q, %v, want %q, %v", tt.n, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHeaderLines(t *testing.T) {
+	lines := strings.Split(commitDiff, "\n")
+	got := HeaderLines(lines)
+	for i, line := range lines {
+		want := i < 4 || (i >= 9 && i < 13)
+		if got[i] != want {
+			t.Errorf("line %d %q: header = %v, want %v", i, line, got[i], want)
+		}
+	}
+}
+
+func TestCountChanges(t *testing.T) {
+	tests := []struct {
+		name           string
+		diff           string
+		added, removed int
+	}{
+		{"multiple files", commitDiff, 3, 2},
+		{"body without headers", "@@ -1 +1 @@\n--- a\n++++ b", 1, 1},
+		{"empty", "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := CountChanges(tt.diff)
+			if added != tt.added || removed != tt.removed {
+				t.Errorf("CountChanges() = +%d -%d, want +%d -%d", added, removed, tt.added, tt.removed)
+			}
+		})
+	}
+}
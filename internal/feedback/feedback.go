@@ -0,0 +1,189 @@
+// Package feedback writes review comments on changed code to a file that an
+// agent can read as its next prompt.
+package feedback
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Comment is a review note attached to a range of lines in a changed file.
+type Comment struct {
+	File      string    `json:"file"` // path relative to its repository
+	Repo      string    `json:"repo,omitempty"`
+	StartLine int       `json:"start_line"`
+	EndLine   int       `json:"end_line"`
+	Code      string    `json:"code"` // the quoted hunk, in unified diff form
+	Comment   string    `json:"comment"`
+	Time      time.Time `json:"time"`
+}
+
+// Location returns "file:start-end", or "file:line" for a single line.
+func (c Comment) Location() string {
+	if c.EndLine > c.StartLine {
+		return fmt.Sprintf("%s:%d-%d", c.File, c.StartLine, c.EndLine)
+	}
+	return fmt.Sprintf("%s:%d", c.File, c.StartLine)
+}
+
+// Write replaces the feedback file with the given comments. The format follows
+// the extension: ".json" writes an array, ".jsonl" one object per line, and
+// anything else Markdown. Writing no comments removes the file.
+func Write(path string, comments []Comment) error {
+	if len(comments) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	var data []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		data, err = json.MarshalIndent(comments, "", "  ")
+		data = append(data, '\n')
+	case ".jsonl":
+		data, err = marshalLines(comments)
+	default:
+		data = []byte(markdown(comments))
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Read loads the comments from a feedback file written by Write, so a new
+// session carries on where the last one stopped. A missing file holds no
+// comments. Markdown does not record when comments were made.
+func Read(path string) ([]Comment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var comments []Comment
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &comments)
+	case ".jsonl":
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			var c Comment
+			if err = json.Unmarshal([]byte(line), &c); err != nil {
+				break
+			}
+			comments = append(comments, c)
+		}
+	default:
+		comments = parseMarkdown(string(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return comments, nil
+}
+
+func marshalLines(comments []Comment) ([]byte, error) {
+	var b strings.Builder
+	for _, c := range comments {
+		line, err := json.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(line)
+		b.WriteString("\n")
+	}
+	return []byte(b.String()), nil
+}
+
+func markdown(comments []Comment) string {
+	var b strings.Builder
+	b.WriteString("# Review feedback\n\n")
+	b.WriteString("Address each comment below. Line numbers refer to the current version of the file.\n")
+	for _, c := range comments {
+		b.WriteString("\n## ")
+		if c.Repo != "" {
+			b.WriteString(c.Repo + ": ")
+		}
+		b.WriteString(c.Location() + "\n\n")
+		b.WriteString("```diff\n" + c.Code + "\n```\n\n")
+		b.WriteString(c.Comment + "\n")
+	}
+	return b.String()
+}
+
+// parseMarkdown reads back the sections written by markdown: a "## " heading
+// with the optional repo and the location, the quoted code and the comment.
+func parseMarkdown(text string) []Comment {
+	var comments []Comment
+	var current *Comment
+	var body []string
+	inCode := false
+	finish := func() {
+		if current != nil {
+			current.Comment = strings.TrimSpace(strings.Join(body, "\n"))
+			comments = append(comments, *current)
+		}
+		body = nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case inCode && line == "```":
+			inCode = false
+		case inCode:
+			if current.Code != "" {
+				current.Code += "\n"
+			}
+			current.Code += line
+		case strings.HasPrefix(line, "## "):
+			finish()
+			current = parseHeading(strings.TrimPrefix(line, "## "))
+		case current != nil && line == "```diff":
+			inCode = true
+		case current != nil:
+			body = append(body, line)
+		}
+	}
+	finish()
+	return comments
+}
+
+// parseHeading reads "repo: file:start-end" or "file:line".
+func parseHeading(heading string) *Comment {
+	c := &Comment{}
+	if repo, rest, ok := strings.Cut(heading, ": "); ok {
+		c.Repo, heading = repo, rest
+	}
+	c.File = heading
+	i := strings.LastIndex(heading, ":")
+	if i < 0 {
+		return c
+	}
+	start, end, _ := strings.Cut(heading[i+1:], "-")
+	first, err := strconv.Atoi(start)
+	if err != nil {
+		return c
+	}
+	c.File, c.StartLine, c.EndLine = heading[:i], first, first
+	if last, err := strconv.Atoi(end); err == nil {
+		c.EndLine = last
+	}
+	return c
+}
@@ -0,0 +1,114 @@
+package feedback
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var comments = []Comment{
+	{
+		File:      "internal/model/model.go",
+		Repo:      "api",
+		StartLine: 12,
+		EndLine:   18,
+		Code:      "@@ -10,3 +12,7 @@\n func f() {\n+\treturn nil\n }",
+		Comment:   "Handle the error here.\n\nAnd log it.",
+		Time:      time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC),
+	},
+	{
+		File:      "README.md",
+		StartLine: 4,
+		EndLine:   4,
+		Code:      "+Some: text",
+		Comment:   "Typo",
+		Time:      time.Date(2026, 10, 18, 9, 31, 0, 0, time.UTC),
+	},
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		keepTime bool
+	}{
+		{"markdown", "feedback.md", false},
+		{"json", "feedback.json", true},
+		{"json lines", "feedback.jsonl", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "vibewatch", tt.file)
+			if err := Write(path, comments); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Read(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := append([]Comment(nil), comments...)
+			for i := range want {
+				if !tt.keepTime {
+					want[i].Time = time.Time{}
+				} else if i < len(got) && got[i].Time.Equal(want[i].Time) {
+					got[i].Time = want[i].Time
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Read() =\n%+v\nwant\n%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteNothingRemovesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feedback.md")
+	if err := Write(path, comments); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(path, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file still exists after writing no comments: %v", err)
+	}
+	if err := Write(path, nil); err != nil {
+		t.Errorf("writing no comments without a file: %v", err)
+	}
+}
+
+func TestReadMissingFile(t *testing.T) {
+	got, err := Read(filepath.Join(t.TempDir(), "feedback.md"))
+	if err != nil || got != nil {
+		t.Errorf("Read() = %v, %v, want no comments", got, err)
+	}
+}
+
+func TestReadMalformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feedback.jsonl")
+	if err := os.WriteFile(path, []byte("{\"file\": \"a.go\"}\nnot json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path); err == nil {
+		t.Error("Read() of a malformed file did not fail")
+	}
+}
+
+func TestParseHeading(t *testing.T) {
+	tests := []struct {
+		heading string
+		want    Comment
+	}{
+		{"main.go:7", Comment{File: "main.go", StartLine: 7, EndLine: 7}},
+		{"api: main.go:7-9", Comment{Repo: "api", File: "main.go", StartLine: 7, EndLine: 9}},
+		{"notes", Comment{File: "notes"}},
+	}
+	for _, tt := range tests {
+		if got := parseHeading(tt.heading); !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("parseHeading(%q) = %+v, want %+v", tt.heading, *got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Package hooks runs user-configured shell commands when a batch of file
+// changes settles, and reports their results.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/config"
+)
+
+// maxOutput is the number of trailing output bytes kept per run.
+const maxOutput = 4096
+
+// waitDelay is how long a finished or cancelled command may keep its output
+// open, for example through a background child, before Wait gives up on it.
+const waitDelay = time.Second
+
+// Status is the state of a hook run.
+type Status int
+
+const (
+	StatusRunning Status = iota
+	StatusPassed
+	StatusFailed
+)
+
+// refPattern matches "path/to/file.ext:line" references in command output.
+var refPattern = regexp.MustCompile(`([A-Za-z0-9_./-]+\.[A-Za-z0-9]+):(\d+)`)
+
+// Ref is a file location mentioned in a failed run's output.
+type Ref struct {
+	Path string // absolute path
+	Line int
+}
+
+// Result describes a single hook run.
+type Result struct {
+	Hook     string
+	Files    []string // absolute paths of the changes that triggered the run
+	Status   Status
+	Output   string // combined stdout/stderr, truncated to the last maxOutput bytes
+	Refs     []Ref  // existing files referenced by the output of a failed run
+	Started  time.Time
+	Duration time.Duration
+}
+
+// Runner executes hooks for settled batches of changes. A hook that is still
+// running when a new matching batch arrives is cancelled and restarted.
+type Runner struct {
+	root    string
+	hooks   []config.Hook
+	results chan Result
+	done    chan struct{} // closed by Close
+	mu      sync.Mutex
+	runs    map[string]run
+	nextRun uint64
+	closed  bool
+}
+
+// run is the in-flight execution of a hook. The id tells a run apart from a
+// newer run of the same hook that replaced it.
+type run struct {
+	id     uint64
+	cancel context.CancelFunc
+}
+
+// New creates a Runner that executes hooks from the root directory.
+func New(root string, hooks []config.Hook) *Runner {
+	return &Runner{
+		root:    root,
+		hooks:   hooks,
+		results: make(chan Result, 16),
+		done:    make(chan struct{}),
+		runs:    make(map[string]run),
+	}
+}
+
+// Results returns a read-only channel of hook run updates.
+func (r *Runner) Results() <-chan Result {
+	return r.results
+}
+
+// Run starts every hook that matches at least one of the given paths.
+func (r *Runner) Run(paths []string) {
+	for _, h := range r.hooks {
+		files := r.matching(h.Match, paths)
+		if len(files) == 0 {
+			continue
+		}
+		r.start(h, files)
+	}
+}
+
+// RunAll starts every hook regardless of its match pattern.
+func (r *Runner) RunAll() {
+	for _, h := range r.hooks {
+		r.start(h, nil)
+	}
+}
+
+// Debounced returns a callback that collects settled batches and runs the
+// matching hooks once no new batch has arrived for the quiet period.
+func (r *Runner) Debounced(quiet time.Duration) func(paths []string) {
+	var (
+		mu      sync.Mutex
+		pending = make(map[string]struct{})
+		timer   *time.Timer
+	)
+	return func(paths []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, p := range paths {
+			pending[p] = struct{}{}
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(quiet, func() {
+			mu.Lock()
+			batch := make([]string, 0, len(pending))
+			for p := range pending {
+				batch = append(batch, p)
+			}
+			pending = make(map[string]struct{})
+			mu.Unlock()
+			r.Run(batch)
+		})
+	}
+}
+
+// Close cancels all running hooks.
+func (r *Runner) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	close(r.done)
+	for name, run := range r.runs {
+		run.cancel()
+		delete(r.runs, name)
+	}
+}
+
+func (r *Runner) matching(pattern string, paths []string) []string {
+	var files []string
+	for _, path := range paths {
+		rel, err := filepath.Rel(r.root, path)
+		if err != nil {
+			continue
+		}
+		if Match(pattern, rel) {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+func (r *Runner) start(h config.Hook, files []string) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	if prev, ok := r.runs[h.Name]; ok {
+		prev.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.nextRun++
+	id := r.nextRun
+	r.runs[h.Name] = run{id: id, cancel: cancel}
+	r.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		started := time.Now()
+		r.sendUpdate(Result{Hook: h.Name, Files: files, Status: StatusRunning, Started: started})
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", expandFiles(h.Command, r.root, files))
+		cmd.Dir = r.root
+		cmd.WaitDelay = waitDelay
+		setProcessGroup(cmd)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		err := cmd.Run()
+
+		// A cancelled run has been superseded by a newer batch
+		if ctx.Err() != nil {
+			return
+		}
+
+		r.mu.Lock()
+		if r.runs[h.Name].id == id {
+			delete(r.runs, h.Name)
+		}
+		r.mu.Unlock()
+
+		res := Result{
+			Hook:     h.Name,
+			Files:    files,
+			Status:   StatusPassed,
+			Output:   truncate(out.String()),
+			Started:  started,
+			Duration: time.Since(started),
+		}
+		if err != nil {
+			res.Status = StatusFailed
+			res.Refs = r.parseRefs(out.String())
+		}
+		r.sendFinal(res)
+	}()
+}
+
+// sendUpdate reports that a run started. It is dropped rather than blocking
+// the hook when nobody is reading, since the final result replaces it.
+func (r *Runner) sendUpdate(res Result) {
+	select {
+	case r.results <- res:
+	default:
+	}
+}
+
+// sendFinal reports the outcome of a run. It is never dropped, or a run would
+// show as running forever; it only gives up once the Runner is closed.
+func (r *Runner) sendFinal(res Result) {
+	select {
+	case r.results <- res:
+	case <-r.done:
+	}
+}
+
+// Match reports whether the slash-separated relative path matches the pattern.
+// A trailing "/**" matches everything below a directory, and patterns without
+// a slash are also matched against the base name.
+func Match(pattern, rel string) bool {
+	if pattern == "" {
+		return true
+	}
+	rel = filepath.ToSlash(rel)
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return rel == prefix || strings.HasPrefix(rel, prefix+"/")
+	}
+	if ok, _ := filepath.Match(pattern, rel); ok {
+		return true
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := filepath.Match(pattern, filepath.Base(rel))
+		return ok
+	}
+	return false
+}
+
+// expandFiles replaces {files} with the shell-quoted relative paths.
+func expandFiles(command, root string, files []string) string {
+	if !strings.Contains(command, "{files}") {
+		return command
+	}
+	quoted := make([]string, 0, len(files))
+	for _, f := range files {
+		rel, err := filepath.Rel(root, f)
+		if err != nil {
+			rel = f
+		}
+		quoted = append(quoted, "'"+strings.ReplaceAll(rel, "'", `'\''`)+"'")
+	}
+	return strings.ReplaceAll(command, "{files}", strings.Join(quoted, " "))
+}
+
+// parseRefs extracts the distinct existing files referenced in output.
+func (r *Runner) parseRefs(output string) []Ref {
+	var refs []Ref
+	seen := make(map[Ref]bool)
+	for _, match := range refPattern.FindAllStringSubmatch(output, -1) {
+		path := match[1]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.root, path)
+		}
+		line, _ := strconv.Atoi(match[2])
+		ref := Ref{Path: filepath.Clean(path), Line: line}
+		if seen[ref] {
+			continue
+		}
+		if info, err := os.Stat(ref.Path); err != nil || info.IsDir() {
+			continue
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func truncate(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= maxOutput {
+		return s
+	}
+	return "..." + s[len(s)-maxOutput:]
+}
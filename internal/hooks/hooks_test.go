@@ -0,0 +1,185 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/config"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"", "any/file.go", true},
+		{"*.go", "main.go", true},
+		{"*.go", "internal/model/model.go", true},
+		{"*.go", "README.md", false},
+		{"pkg/**", "pkg", true},
+		{"pkg/**", "pkg/a/b.go", true},
+		{"pkg/**", "pkgs/a.go", false},
+		{"internal/*.go", "internal/main.go", true},
+		{"internal/*.go", "internal/model/model.go", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.rel); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestExpandFiles(t *testing.T) {
+	tests := []struct {
+		command string
+		files   []string
+		want    string
+	}{
+		{"go vet ./...", []string{"/repo/a.go"}, "go vet ./..."},
+		{"lint {files}", []string{"/repo/a.md", "/repo/docs/b.md"}, "lint 'a.md' 'docs/b.md'"},
+		{"lint {files}", []string{"/repo/it's.md"}, `lint 'it'\''s.md'`},
+		{"lint {files}", nil, "lint "},
+	}
+	for _, tt := range tests {
+		if got := expandFiles(tt.command, "/repo", tt.files); got != tt.want {
+			t.Errorf("expandFiles(%q, %q) = %q, want %q", tt.command, tt.files, got, tt.want)
+		}
+	}
+}
+
+func TestRunnerResults(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "main.go"), "package main\n")
+
+	tests := []struct {
+		name   string
+		hook   config.Hook
+		status Status
+		output string
+		refs   []Ref
+	}{
+		{
+			name:   "passed",
+			hook:   config.Hook{Name: "vet", Command: "echo ok {files}"},
+			status: StatusPassed,
+			output: "ok main.go",
+		},
+		{
+			name:   "failed",
+			hook:   config.Hook{Name: "test", Command: "echo main.go:3: broken; echo gone.go:1: missing; exit 1"},
+			status: StatusFailed,
+			output: "main.go:3: broken\ngone.go:1: missing",
+			refs:   []Ref{{Path: filepath.Join(root, "main.go"), Line: 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(root, []config.Hook{tt.hook})
+			defer r.Close()
+			r.Run([]string{filepath.Join(root, "main.go")})
+
+			if res := next(t, r); res.Status != StatusRunning {
+				t.Fatalf("first result %v, want running", res.Status)
+			}
+			res := next(t, r)
+			if res.Status != tt.status || res.Output != tt.output || !reflect.DeepEqual(res.Refs, tt.refs) {
+				t.Errorf("result = %v %q %v, want %v %q %v", res.Status, res.Output, res.Refs, tt.status, tt.output, tt.refs)
+			}
+		})
+	}
+}
+
+func TestRunnerSkipsUnmatchedHooks(t *testing.T) {
+	root := t.TempDir()
+	r := New(root, []config.Hook{{Name: "lint", Match: "*.md", Command: "true"}})
+	defer r.Close()
+	r.Run([]string{filepath.Join(root, "main.go")})
+	select {
+	case res := <-r.Results():
+		t.Errorf("unexpected result %+v", res)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestRunnerRestartsSupersededRun(t *testing.T) {
+	root := t.TempDir()
+	r := New(root, []config.Hook{{Name: "slow", Command: "sleep 0.3; echo {files}"}})
+	defer r.Close()
+	r.Run([]string{filepath.Join(root, "a.go")})
+	r.Run([]string{filepath.Join(root, "b.go")})
+
+	var finals []Result
+	deadline := time.After(time.Second)
+collect:
+	for len(finals) < 2 {
+		select {
+		case res := <-r.Results():
+			if res.Status != StatusRunning {
+				finals = append(finals, res)
+			}
+		case <-deadline:
+			break collect
+		}
+	}
+	if len(finals) != 1 || finals[0].Output != "b.go" {
+		t.Errorf("final results = %+v, want only the run for b.go", finals)
+	}
+}
+
+// next returns the runner's next result, failing the test after a timeout.
+func next(t *testing.T, r *Runner) Result {
+	t.Helper()
+	select {
+	case res := <-r.Results():
+		return res
+	case <-time.After(5 * time.Second):
+		t.Fatal("no hook result")
+	}
+	return Result{}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDebouncedRunsOnceAfterQuiet(t *testing.T) {
+	root := t.TempDir()
+	r := New(root, []config.Hook{{Name: "check", Command: "echo {files}"}})
+	defer r.Close()
+	settle := r.Debounced(100 * time.Millisecond)
+
+	settle([]string{filepath.Join(root, "b.go")})
+	time.Sleep(50 * time.Millisecond)
+	settle([]string{filepath.Join(root, "a.go"), filepath.Join(root, "b.go")})
+
+	if res := next(t, r); res.Status != StatusRunning {
+		t.Fatalf("first result %v, want running", res.Status)
+	}
+	if res := next(t, r); res.Output != "a.go b.go" {
+		t.Errorf("output = %q, want one run for both batches", res.Output)
+	}
+	select {
+	case res := <-r.Results():
+		t.Errorf("unexpected second run %+v", res)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestRunAllIgnoresMatch(t *testing.T) {
+	root := t.TempDir()
+	r := New(root, []config.Hook{{Name: "lint", Match: "*.md", Command: "echo {files}"}})
+	defer r.Close()
+	r.RunAll()
+
+	next(t, r)
+	if res := next(t, r); res.Status != StatusPassed || len(res.Files) != 0 {
+		t.Errorf("result = %+v, want a passed run without files", res)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package hooks
+
+import "os/exec"
+
+// setProcessGroup is only implemented on Unix; elsewhere cancelling kills the
+// shell and cmd.WaitDelay stops Wait from blocking on its children.
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package hooks
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in its own process group and makes cancelling it
+// kill the whole group, so the children of compound commands stop as well.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
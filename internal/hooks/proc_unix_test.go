@@ -0,0 +1,42 @@
+//go:build unix
+
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/config"
+)
+
+func TestCloseKillsBackgroundChildren(t *testing.T) {
+	root := t.TempDir()
+	pidFile := filepath.Join(root, "pid")
+	r := New(root, []config.Hook{{Name: "server", Command: "sleep 30 & echo $! > pid; wait"}})
+	r.RunAll()
+
+	var pid int
+	for deadline := time.Now().Add(5 * time.Second); pid == 0; {
+		if data, err := os.ReadFile(pidFile); err == nil && strings.HasSuffix(string(data), "\n") {
+			pid, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+		} else if time.Now().After(deadline) {
+			t.Fatal("the hook did not start its child")
+		} else {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	r.Close()
+	for deadline := time.Now().Add(5 * time.Second); syscall.Kill(pid, 0) == nil; {
+		if time.Now().After(deadline) {
+			syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatal("the hook's background child survived Close")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,43 @@
+// base.go lets the user pick the git ref diffs are taken against, so the
+// timeline can show everything that changed since main, a tag or HEAD~3
+// instead of only what is not yet staged.
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+)
+
+// setBase switches the differ to a new base ref and reloads every file
+// against it. An empty ref goes back to diffing the index.
+func setBase(d differ.Differ, ref string) tea.Cmd {
+	ref = strings.TrimSpace(ref)
+	return func() tea.Msg {
+		err := d.SetBase(ref)
+		entries, dirtyErr := d.DirtyFiles()
+		if dirtyErr != nil {
+			logMessage(fmt.Sprintf("Model: Error reloading against %q: %v", ref, dirtyErr))
+		}
+		return BaseChangedMsg{Base: d.Base(), Entries: entries, Err: err}
+	}
+}
+
+// applyBase replaces the entries with those diffed against the new base and
+// says what they are compared to now.
+func (m *Model) applyBase(msg BaseChangedMsg) tea.Cmd {
+	m.base = msg.Base
+	m.replaceRepoEntries("", msg.Entries)
+	m.syncSelection()
+	m.viewport.SetContent(m.renderEntries())
+	if msg.Err != nil {
+		return m.flash(msg.Err.Error())
+	}
+	if m.base == "" {
+		return m.flash("Diffing against the index")
+	}
+	return m.flash("Diffing against " + m.base)
+}
@@ -0,0 +1,144 @@
+// checks.go renders the checks panel: the last result of each configured
+// check command, shown below the diff list so a single screen shows both what
+// changed and whether it broke the build.
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/hooks"
+)
+
+// checkOutputLines is the number of trailing output lines shown for a failed check.
+const checkOutputLines = 3
+
+func waitForCheckResult(ch <-chan hooks.Result) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		res, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return CheckResultMsg(res)
+	}
+}
+
+// recordCheckResult stores the latest run of a check, keeping first-seen order.
+func (m *Model) recordCheckResult(res hooks.Result) {
+	if _, exists := m.checkRuns[res.Hook]; !exists {
+		m.checkOrder = append(m.checkOrder, res.Hook)
+	}
+	m.checkRuns[res.Hook] = res
+	m.checkLinkIndex = 0
+}
+
+// checksPanelHeight returns the number of lines the checks panel occupies.
+func (m *Model) checksPanelHeight() int {
+	if !m.showChecks || len(m.checkOrder) == 0 {
+		return 0
+	}
+	return strings.Count(m.renderChecks(), "\n") + 1
+}
+
+// checkFiles returns the files involved in a check run: locations referenced
+// by its output first, then the changed files that triggered it.
+func checkFiles(res hooks.Result) []hooks.Ref {
+	refs := append([]hooks.Ref(nil), res.Refs...)
+	for _, f := range res.Files {
+		refs = append(refs, hooks.Ref{Path: f})
+	}
+	return refs
+}
+
+// failedCheckLinks returns the files involved in all failed checks.
+func (m *Model) failedCheckLinks() []hooks.Ref {
+	var links []hooks.Ref
+	for _, name := range m.checkOrder {
+		res := m.checkRuns[name]
+		if res.Status == hooks.StatusFailed {
+			links = append(links, checkFiles(res)...)
+		}
+	}
+	return links
+}
+
+// jumpToCheckFile selects the next file involved in a failed check.
+func (m *Model) jumpToCheckFile() {
+	links := m.failedCheckLinks()
+	if len(links) == 0 {
+		return
+	}
+	for range links {
+		link := links[m.checkLinkIndex%len(links)]
+		m.checkLinkIndex++
+		if m.selectFile(link.Path) {
+			return
+		}
+	}
+}
+
+func (m *Model) renderChecks() string {
+	maxLines := m.height / 3
+	if maxLines < 2 {
+		maxLines = 2
+	}
+
+	lines := []string{CheckTitleStyle.Render(" Checks")}
+	for _, name := range m.checkOrder {
+		res := m.checkRuns[name]
+		line := " " + hookBadge(res)
+		if res.Status != hooks.StatusRunning {
+			line += TimestampStyle.Render(fmt.Sprintf("  %s  %s", res.Duration.Round(100*time.Millisecond), res.Started.Format("15:04:05")))
+		}
+		if res.Status == hooks.StatusFailed {
+			var names []string
+			for _, ref := range checkFiles(res) {
+				name := m.relPath(ref.Path)
+				if ref.Line > 0 {
+					name = fmt.Sprintf("%s:%d", name, ref.Line)
+				}
+				names = append(names, name)
+			}
+			if len(names) > 0 {
+				line += "  " + CheckLinkStyle.Render("→ "+strings.Join(names, ", "))
+			}
+		}
+		lines = append(lines, line)
+
+		if res.Status == hooks.StatusFailed && res.Output != "" {
+			output := strings.Split(res.Output, "\n")
+			if len(output) > checkOutputLines {
+				output = output[len(output)-checkOutputLines:]
+			}
+			for _, l := range output {
+				lines = append(lines, ContextLineStyle.Render("     "+l))
+			}
+		}
+	}
+
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+	for i, l := range lines {
+		lines[i] = lipgloss.NewStyle().MaxWidth(m.width).Render(l)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// relPath shortens an absolute path to be relative to the repo it belongs to.
+func (m *Model) relPath(path string) string {
+	for root := range m.differ.RepoRootsWithNames() {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return path
+}
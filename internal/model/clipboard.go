@@ -0,0 +1,72 @@
+// clipboard.go copies paths, hunks and diffs to the system clipboard using
+// OSC52 escape sequences, which the terminal forwards even over SSH.
+package model
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// copyToClipboard writes text to the clipboard through the terminal at w,
+// wrapping the sequence for tmux and screen when running inside them.
+func copyToClipboard(w io.Writer, text string) error {
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	_, err := seq.WriteTo(w)
+	return err
+}
+
+// copyText copies text and reports the outcome in the status bar.
+func (m *Model) copyText(text, what string) tea.Cmd {
+	if err := copyToClipboard(m.terminal, text); err != nil {
+		return m.flash("Copy failed: " + err.Error())
+	}
+	return m.flash("Copied " + what)
+}
+
+// copySelectedPath copies the selected entry's file path.
+func (m *Model) copySelectedPath() tea.Cmd {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return m.flash("No file selected")
+	}
+	if e.Commit != nil {
+		return m.copyText(e.Commit.Hash, "commit hash")
+	}
+	return m.copyText(e.FilePath, "path")
+}
+
+// copyCurrentHunk copies the current hunk, preceded by the file it belongs to.
+func (m *Model) copyCurrentHunk() tea.Cmd {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return m.flash("No file selected")
+	}
+	h, ok := m.currentHunk()
+	if !ok {
+		return m.flash("No hunk to copy")
+	}
+	text := fmt.Sprintf("%s\n%s\n%s", m.relPath(e.FilePath), h.Header, strings.Join(h.Lines, "\n"))
+	return m.copyText(text, fmt.Sprintf("hunk %d of %s", m.currentHunkIndex()+1, m.relPath(e.FilePath)))
+}
+
+// copySelectedDiff copies the selected entry's whole diff.
+func (m *Model) copySelectedDiff() tea.Cmd {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return m.flash("No file selected")
+	}
+	if e.Diff == "" {
+		return m.flash("No diff to copy")
+	}
+	return m.copyText(e.Diff, "diff of "+m.relPath(e.FilePath))
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("terminal gone") }
+
+func TestCopyText(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm-256color")
+
+	root := t.TempDir()
+	initRepo(t, root)
+	d, err := differ.NewGit(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	m := newTestModel(d)
+	m.terminal = &out
+	m.copyText("main.go", "path")
+	if want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("main.go")) + "\x07"; out.String() != want {
+		t.Errorf("wrote %q, want %q", out.String(), want)
+	}
+	if m.statusMessage != "Copied path" {
+		t.Errorf("status = %q, want %q", m.statusMessage, "Copied path")
+	}
+
+	m.terminal = failingWriter{}
+	m.copyText("main.go", "path")
+	if !strings.HasPrefix(m.statusMessage, "Copy failed: terminal gone") {
+		t.Errorf("status = %q after a failed write", m.statusMessage)
+	}
+}
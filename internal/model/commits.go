@@ -0,0 +1,96 @@
+// commits.go shows commits made during the session as timeline entries, so
+// committed work stays visible after its files drop out of the list of
+// changes. A commit's diff starts hidden and opens like any other.
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/watcher"
+)
+
+// maxCommitFiles is how many file names a commit header lists before
+// summarizing the rest.
+const maxCommitFiles = 5
+
+// loadCommit reads the commit a git operation created.
+func loadCommit(d differ.Differ, ev watcher.Event) tea.Cmd {
+	root := repoRoot(d, ev.Repo)
+	if root == "" || ev.Git.Commit == "" || ev.Git.Submodule != "" {
+		return nil
+	}
+	_, single := d.(*differ.GitDiffer)
+	return func() tea.Msg {
+		entry, err := differ.ShowCommit(root, ev.Git.Commit)
+		if err != nil {
+			logMessage(fmt.Sprintf("Model: Error reading commit %s: %v", ev.Git.Commit, err))
+			return nil
+		}
+		entry.Timestamp = ev.Time
+		if !single {
+			entry.Repo = ev.Repo
+		}
+		return CommitMsg(entry)
+	}
+}
+
+// repoRoot returns the root of the repository with the given name.
+func repoRoot(d differ.Differ, repo string) string {
+	for root, name := range d.RepoRootsWithNames() {
+		if name == repo {
+			return root
+		}
+	}
+	return ""
+}
+
+// addCommit puts a commit at the top of the timeline with its diff hidden.
+func (m *Model) addCommit(entry types.DiffEntry) {
+	m.entries = removeEntriesForFile(m.entries, entry.FilePath)
+	m.entries = append([]types.DiffEntry{entry}, m.entries...)
+	if len(m.entries) > m.maxEntries {
+		m.entries = m.entries[:m.maxEntries]
+	}
+	m.visibleFilesMu.Lock()
+	if _, ok := m.visibleFiles[entry.FilePath]; !ok {
+		m.visibleFiles[entry.FilePath] = false
+		m.showHiddenCount++
+	}
+	m.visibleFilesMu.Unlock()
+}
+
+// commitTitle is the header text of a commit entry.
+func commitTitle(c *types.Commit) string {
+	return "● " + shortHash(c.Hash) + " " + c.Message
+}
+
+// renderCommitInfo renders the line below a commit's header with its author
+// and files.
+func renderCommitInfo(c *types.Commit) string {
+	files := c.Files
+	more := ""
+	if len(files) > maxCommitFiles {
+		more = fmt.Sprintf(" and %d more", len(files)-maxCommitFiles)
+		files = files[:maxCommitFiles]
+	}
+	noun := "files"
+	if len(c.Files) == 1 {
+		noun = "file"
+	}
+	info := fmt.Sprintf("  by %s · %d %s: %s%s", c.Author, len(c.Files), noun, strings.Join(files, ", "), more)
+	return TimestampStyle.Render(info) + "\n"
+}
+
+// commitDiffPath returns the file a "diff --git a/x b/x" line of a commit's
+// diff introduces.
+func commitDiffPath(line string) string {
+	if _, path, ok := strings.Cut(line, " b/"); ok {
+		return path
+	}
+	return strings.TrimPrefix(line, "diff --git ")
+}
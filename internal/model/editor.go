@@ -0,0 +1,113 @@
+// editor.go opens the selected entry in the user's editor at the first
+// changed line, or formats an editor URI for GUI editors.
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+)
+
+// defaultEditorURI is used when the config does not set editor_uri.
+const defaultEditorURI = "vscode://file{path}:{line}"
+
+// selectedEntry returns the entry for the selected file, if any.
+func (m *Model) selectedEntry() (types.DiffEntry, bool) {
+	if m.selectedFilePath == "" {
+		return types.DiffEntry{}, false
+	}
+	for _, e := range m.entries {
+		if e.FilePath == m.selectedFilePath {
+			return e, true
+		}
+	}
+	return types.DiffEntry{}, false
+}
+
+// changedLine returns the line to open an entry at, taken from its current hunk.
+func (m *Model) changedLine(e types.DiffEntry) int {
+	hunks := differ.ParseHunks(m.displayDiff(e))
+	if len(hunks) == 0 {
+		return 1
+	}
+	idx := 0
+	if e.FilePath == m.selectedFilePath && m.currentHunkIndex() < len(hunks) {
+		idx = m.currentHunkIndex()
+	}
+	return hunks[idx].FirstChangedLine()
+}
+
+// editorCommand builds the command that opens path at line in $VISUAL or
+// $EDITOR, using the line syntax the editor understands.
+func editorCommand(path string, line int) *exec.Cmd {
+	fields := strings.Fields(os.Getenv("VISUAL"))
+	if len(fields) == 0 {
+		fields = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(fields) == 0 {
+		fields = []string{"vi"}
+	}
+
+	args := fields[1:]
+	switch filepath.Base(fields[0]) {
+	case "code", "code-insiders", "codium", "cursor", "windsurf":
+		args = append(args, "--wait", "--goto", fmt.Sprintf("%s:%d", path, line))
+	case "subl", "zed", "hx", "helix":
+		args = append(args, fmt.Sprintf("%s:%d", path, line))
+	default:
+		// vi, vim, nvim, nano, emacs, micro, kak and most terminal editors
+		args = append(args, "+"+strconv.Itoa(line), path)
+	}
+	return exec.Command(fields[0], args...)
+}
+
+// openInEditor suspends the TUI and opens the selected entry in the editor.
+func (m *Model) openInEditor() tea.Cmd {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return m.flash("No file selected")
+	}
+	if e.Commit != nil {
+		return m.flash("Commits cannot be opened in the editor")
+	}
+	if _, err := os.Stat(e.FilePath); err != nil {
+		return m.flash("Cannot open " + e.FilePath + ": file does not exist")
+	}
+
+	cmd := editorCommand(e.FilePath, m.changedLine(e))
+	logMessage(fmt.Sprintf("Model: Opening editor: %s", strings.Join(cmd.Args, " ")))
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return EditorFinishedMsg{Err: err}
+	})
+}
+
+// editorURI fills the {path} and {line} placeholders of the URI template.
+func editorURI(template, path string, line int) string {
+	if template == "" {
+		template = defaultEditorURI
+	}
+	escaped := (&url.URL{Path: filepath.ToSlash(path)}).EscapedPath()
+	uri := strings.ReplaceAll(template, "{path}", escaped)
+	return strings.ReplaceAll(uri, "{line}", strconv.Itoa(line))
+}
+
+// showEditorURI displays the editor URI for the selected entry in the status bar.
+func (m *Model) showEditorURI() tea.Cmd {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return m.flash("No file selected")
+	}
+	if e.Commit != nil {
+		return m.flash("Commits cannot be opened in the editor")
+	}
+	return m.flash(editorURI(m.editorURITemplate, e.FilePath, m.changedLine(e)))
+}
@@ -0,0 +1,128 @@
+// expand.go lets the user see more of a diff: expanding entries past the
+// truncation limit, collapsing individual hunks, and fetching more context
+// lines around the changes on demand.
+package model
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+)
+
+// contextStep is how many context lines each expand or shrink action adds or removes.
+const contextStep = 10
+
+// toggleExpanded shows the selected entry's whole diff, or truncates it again.
+func (m *Model) toggleExpanded() tea.Cmd {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return m.flash("No file selected")
+	}
+	if m.expandedEntries[e.FilePath] {
+		delete(m.expandedEntries, e.FilePath)
+	} else {
+		m.expandedEntries[e.FilePath] = true
+	}
+	m.viewport.SetContent(m.renderEntries())
+	return nil
+}
+
+// isHunkCollapsed reports whether a hunk of the file's full diff is collapsed.
+func (m *Model) isHunkCollapsed(filePath string, h differ.Hunk) bool {
+	return m.collapsedHunks[filePath][hunkHash(h)]
+}
+
+// toggleHunkCollapsed collapses or expands the current hunk.
+func (m *Model) toggleHunkCollapsed() tea.Cmd {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return m.flash("No file selected")
+	}
+	h, ok := m.currentFullHunk()
+	if !ok {
+		return m.flash("No hunk to collapse")
+	}
+	hash := hunkHash(h)
+	if m.collapsedHunks[e.FilePath][hash] {
+		delete(m.collapsedHunks[e.FilePath], hash)
+	} else {
+		if m.collapsedHunks[e.FilePath] == nil {
+			m.collapsedHunks[e.FilePath] = make(map[uint64]bool)
+		}
+		m.collapsedHunks[e.FilePath][hash] = true
+	}
+	m.viewport.SetContent(m.renderEntries())
+	return nil
+}
+
+// contextFor returns the number of context lines used for a file.
+func (m *Model) contextFor(filePath string) int {
+	if n, ok := m.contextLines[filePath]; ok {
+		return n
+	}
+	return m.defaultContext
+}
+
+// changeContext fetches the selected entry's diff again with more (delta > 0)
+// or fewer (delta < 0) context lines.
+func (m *Model) changeContext(delta int) tea.Cmd {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return m.flash("No file selected")
+	}
+	if e.Commit != nil {
+		return m.flash("The context of a commit's diff cannot be changed")
+	}
+	n := max(m.contextFor(e.FilePath)+delta*contextStep, 0)
+	if delta < 0 && n < m.defaultContext {
+		n = m.defaultContext
+	}
+	if n == m.defaultContext {
+		delete(m.contextLines, e.FilePath)
+	} else {
+		m.contextLines[e.FilePath] = n
+	}
+	return tea.Batch(
+		m.flash(fmt.Sprintf("Showing %d lines of context for %s", n, m.relPath(e.FilePath))),
+		fetchContextDiff(m.differ, e.FilePath, n),
+		m.computeSinceLook(e.FilePath),
+	)
+}
+
+// refetchContext re-diffs a changed file that has a custom context size.
+func (m *Model) refetchContext(filePath string) tea.Cmd {
+	n, ok := m.contextLines[filePath]
+	if !ok {
+		return nil
+	}
+	return fetchContextDiff(m.differ, filePath, n)
+}
+
+func fetchContextDiff(d differ.Differ, filePath string, context int) tea.Cmd {
+	return func() tea.Msg {
+		entry, err := d.DiffContext(filePath, context)
+		if err != nil {
+			return nil
+		}
+		return ContextDiffMsg(entry)
+	}
+}
+
+// replaceDiff swaps in a re-fetched diff for an entry, keeping its position
+// and timestamp.
+func (m *Model) replaceDiff(entry types.DiffEntry) {
+	for i, e := range m.entries {
+		if e.FilePath == entry.FilePath {
+			if entry.Diff == "" && !entry.IsNew {
+				return
+			}
+			m.entries[i].Diff = entry.Diff
+			m.entries[i].Error = entry.Error
+			m.viewport.SetContent(m.renderEntries())
+			return
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// feedback.go collects review comments on hunks and lines and writes them to
+// the feedback file, turning the diff view into a review loop with the agent.
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/feedback"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+)
+
+// loadFeedback reads the comments an earlier session left in the feedback
+// file, so new comments are added to them rather than replacing them.
+func loadFeedback(path string) tea.Cmd {
+	return func() tea.Msg {
+		comments, err := feedback.Read(path)
+		return FeedbackLoadedMsg{Comments: comments, Err: err}
+	}
+}
+
+// commentTarget returns the selected entry and current hunk to comment on,
+// or a command explaining why there is none.
+func (m *Model) commentTarget() (types.DiffEntry, differ.Hunk, tea.Cmd) {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return e, differ.Hunk{}, m.flash("No file selected")
+	}
+	if e.Commit != nil {
+		return e, differ.Hunk{}, m.flash("Comments are for uncommitted changes")
+	}
+	h, ok := m.currentHunk()
+	if !ok {
+		return e, h, m.flash("No hunk to comment on")
+	}
+	return e, h, nil
+}
+
+// startComment opens the prompt for a comment on the current hunk.
+func (m *Model) startComment() tea.Cmd {
+	e, h, cmd := m.commentTarget()
+	if cmd != nil {
+		return cmd
+	}
+	start, end := h.ChangedRange()
+	m.pendingComment = feedback.Comment{
+		File:      m.relPath(e.FilePath),
+		Repo:      e.Repo,
+		StartLine: start,
+		EndLine:   end,
+		Code:      h.Header + "\n" + strings.Join(h.Lines, "\n"),
+	}
+	label := fmt.Sprintf(" Comment on %s: ", m.pendingComment.Location())
+	return m.openPrompt(promptComment, label, "")
+}
+
+// startLineComment asks which line of the current hunk to comment on,
+// starting at its first changed line.
+func (m *Model) startLineComment() tea.Cmd {
+	_, h, cmd := m.commentTarget()
+	if cmd != nil {
+		return cmd
+	}
+	last := max(h.NewStart, h.NewStart+h.NewLines-1)
+	label := fmt.Sprintf(" Line to comment on (%d-%d): ", h.NewStart, last)
+	return m.openPrompt(promptCommentLine, label, strconv.Itoa(h.FirstChangedLine()))
+}
+
+// commentOnLine opens the comment prompt for one line of the current hunk,
+// quoting that line and the lines it replaced.
+func (m *Model) commentOnLine(value string) tea.Cmd {
+	e, h, cmd := m.commentTarget()
+	if cmd != nil {
+		return cmd
+	}
+	line, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return m.flash(fmt.Sprintf("Not a line number: %q", value))
+	}
+	quoted, ok := h.LineAt(line)
+	if !ok {
+		return m.flash(fmt.Sprintf("Line %d is not in the current hunk", line))
+	}
+	m.pendingComment = feedback.Comment{
+		File:      m.relPath(e.FilePath),
+		Repo:      e.Repo,
+		StartLine: line,
+		EndLine:   line,
+		Code:      h.Header + "\n" + strings.Join(quoted, "\n"),
+	}
+	label := fmt.Sprintf(" Comment on %s: ", m.pendingComment.Location())
+	return m.openPrompt(promptComment, label, "")
+}
+
+// addComment stores the pending comment with its text and rewrites the feedback file.
+func (m *Model) addComment(text string) tea.Cmd {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	c := m.pendingComment
+	c.Comment = text
+	c.Time = time.Now()
+	m.comments = append(m.comments, c)
+	m.viewport.SetContent(m.renderEntries())
+
+	if err := feedback.Write(m.feedbackPath, m.comments); err != nil {
+		return m.flash("Writing feedback failed: " + err.Error())
+	}
+	return m.flash(fmt.Sprintf("Comment saved (%d total) to %s", len(m.comments), m.feedbackPath))
+}
+
+// clearComments discards all comments and removes the feedback file.
+func (m *Model) clearComments() tea.Cmd {
+	m.comments = nil
+	m.viewport.SetContent(m.renderEntries())
+	if err := feedback.Write(m.feedbackPath, nil); err != nil {
+		return m.flash("Clearing feedback failed: " + err.Error())
+	}
+	return m.flash("Feedback cleared")
+}
+
+// commentCount returns the number of comments on a file.
+func (m *Model) commentCount(relPath, repo string) int {
+	count := 0
+	for _, c := range m.comments {
+		if c.File == relPath && c.Repo == repo {
+			count++
+		}
+	}
+	return count
+}
+
+// renderFeedback renders the overlay listing all collected comments.
+func (m *Model) renderFeedback() string {
+	var b strings.Builder
+	b.WriteString("  Feedback (" + m.feedbackPath + "):\n")
+	b.WriteString("  ───────────────────────────────\n")
+	if len(m.comments) == 0 {
+		fmt.Fprintf(&b, "  No comments yet. Press %s on a hunk to add one.", keyHint(m.keys.Comment))
+		return helpStyle.Render(b.String())
+	}
+	for i, c := range m.comments {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		loc := c.Location()
+		if c.Repo != "" {
+			loc = c.Repo + ": " + loc
+		}
+		b.WriteString("  " + FilePathStyle.UnsetMarginTop().Render(loc) + "\n")
+		b.WriteString("    " + c.Comment + "\n")
+	}
+	fmt.Fprintf(&b, "\n  %s clears all comments", keyHint(m.keys.ClearFeed))
+	return helpStyle.Render(b.String())
+}
@@ -0,0 +1,122 @@
+// gitops.go reacts to git operations in a watched repository: it reloads the
+// dirty files of that repository only and tells the user what happened.
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/watcher"
+)
+
+// loadRepoEntries reloads the dirty files of a single repository.
+func loadRepoEntries(d differ.Differ, repo string) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := d.RepoDirtyFiles(repo)
+		if err != nil {
+			logMessage(fmt.Sprintf("Model: Error reloading %q: %v", repo, err))
+			return nil
+		}
+		return RepoEntriesMsg{Repo: repo, Entries: entries}
+	}
+}
+
+// rescanEntries reloads the dirty files of every repository, for when events
+// were lost.
+func rescanEntries(d differ.Differ) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := d.DirtyFiles()
+		if err != nil {
+			logMessage(fmt.Sprintf("Model: Error rescanning: %v", err))
+			return nil
+		}
+		return RepoEntriesMsg{Entries: entries}
+	}
+}
+
+// replaceRepoEntries swaps the changed files of one repository, or of all of
+// them when repo is "", for a fresh set, keeping commits and the other
+// repositories' entries as they are. Files that are still changed keep their
+// place in the timeline.
+func (m *Model) replaceRepoEntries(repo string, entries []types.DiffEntry) {
+	_, single := m.differ.(*differ.GitDiffer)
+	fresh := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		fresh[e.FilePath] = true
+	}
+	kept := make([]types.DiffEntry, 0, len(m.entries)+len(entries))
+	seen := make(map[string]time.Time)
+	for _, e := range m.entries {
+		if e.Commit != nil || (!single && repo != "" && e.Repo != repo) {
+			kept = append(kept, e)
+			continue
+		}
+		seen[e.FilePath] = e.Timestamp
+		if !fresh[e.FilePath] {
+			delete(m.sinceLook, e.FilePath)
+		}
+	}
+	for i, e := range entries {
+		if ts, ok := seen[e.FilePath]; ok {
+			entries[i].Timestamp = ts
+		}
+	}
+	m.entries = append(entries, kept...)
+	sort.SliceStable(m.entries, func(i, j int) bool {
+		return m.entries[i].Timestamp.After(m.entries[j].Timestamp)
+	})
+	if len(m.entries) > m.maxEntries {
+		m.entries = m.entries[:m.maxEntries]
+	}
+	for _, e := range entries {
+		m.pruneReviewed(e)
+	}
+}
+
+// describeGitOperation returns the status bar text for a git operation, or ""
+// when it is not worth mentioning (index-only changes such as git add).
+func describeGitOperation(ev watcher.Event) string {
+	op := ev.Git
+	if op.Kind == watcher.GitIndex {
+		return ""
+	}
+	text := op.Kind.String()
+	if op.Commit != "" && (op.Kind == watcher.GitCommit || op.Kind == watcher.GitReset || op.Kind == watcher.GitMerge) {
+		text += " " + shortHash(op.Commit)
+	}
+	if op.Message != "" {
+		text += " — " + op.Message
+	}
+	if op.Submodule != "" {
+		text = op.Submodule + ": " + text
+	}
+	if ev.Repo != "" {
+		text = ev.Repo + ": " + text
+	}
+	return text
+}
+
+// isSubmoduleDiff reports whether a diff moves a submodule pointer rather
+// than changing a file.
+func isSubmoduleDiff(diff string) bool {
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "+Subproject commit ") || strings.HasPrefix(line, "-Subproject commit ") {
+			return true
+		}
+	}
+	return false
+}
+
+// shortHash abbreviates a commit hash the way git does by default.
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
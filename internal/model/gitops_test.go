@@ -0,0 +1,172 @@
+package model
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/config"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/watcher"
+)
+
+func TestReplaceRepoEntries(t *testing.T) {
+	parent := t.TempDir()
+	rootA, rootB := filepath.Join(parent, "a"), filepath.Join(parent, "b")
+	initRepo(t, rootA)
+	initRepo(t, rootB)
+	single, err := differ.NewGit(rootA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	multi, err := differ.NewMulti(map[string]string{rootA: "a", rootB: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	earlier := time.Now().Add(-time.Hour)
+	existing := []types.DiffEntry{
+		{FilePath: filepath.Join(rootA, "stale.go"), Repo: "a", Timestamp: earlier},
+		{FilePath: filepath.Join(rootA, "kept.go"), Repo: "a", Timestamp: earlier},
+		{FilePath: filepath.Join(rootB, "other.go"), Repo: "b", Timestamp: earlier},
+		{FilePath: "0123456789abcdef", Repo: "a", Timestamp: earlier, Commit: &types.Commit{Hash: "0123456789abcdef"}},
+	}
+	fresh := []types.DiffEntry{
+		{FilePath: filepath.Join(rootA, "kept.go"), Repo: "a", Timestamp: time.Now()},
+		{FilePath: filepath.Join(rootA, "new.go"), Repo: "a", Timestamp: time.Now()},
+	}
+
+	tests := []struct {
+		name   string
+		differ differ.Differ
+		repo   string
+		want   []string
+	}{
+		{"one of several repos", multi, "a", []string{"a/kept.go", "a/new.go", "b/other.go", "0123456789abcdef"}},
+		{"all repos", multi, "", []string{"a/kept.go", "a/new.go", "0123456789abcdef"}},
+		{"single repo", single, "a", []string{"a/kept.go", "a/new.go", "0123456789abcdef"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(tt.differ)
+			m.entries = append([]types.DiffEntry(nil), existing...)
+			m.replaceRepoEntries(tt.repo, append([]types.DiffEntry(nil), fresh...))
+
+			if got := entryNames(parent, m.entries); !reflect.DeepEqual(got, sorted(tt.want)) {
+				t.Errorf("entries = %q, want %q", got, sorted(tt.want))
+			}
+			for _, e := range m.entries {
+				if e.FilePath == filepath.Join(rootA, "kept.go") && !e.Timestamp.Equal(earlier) {
+					t.Errorf("a file that is still changed lost its place in the timeline")
+				}
+			}
+		})
+	}
+}
+
+func TestSubmoduleEntriesSurviveParentReload(t *testing.T) {
+	dir := t.TempDir()
+	sub, root := filepath.Join(dir, "sub"), filepath.Join(dir, "parent")
+	initRepo(t, sub)
+	initRepo(t, root)
+	runGit(t, root, "-c", "protocol.file.allow=always", "submodule", "add", "--quiet", sub, "lib")
+	runGit(t, root, "commit", "--quiet", "-m", "Add lib")
+
+	edited := filepath.Join(root, "lib", "README")
+	writeTestFile(t, edited, "changed inside the submodule\n")
+
+	d, err := differ.NewGit(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := d.DirtyFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(d)
+	m.Update(RepoEntriesMsg{Entries: entries})
+	if !hasEntry(m.entries, edited) {
+		t.Fatalf("startup entries %q lack the submodule file", entryNames(dir, m.entries))
+	}
+
+	// git add in the parent is reported as an index change of the parent repo
+	writeTestFile(t, filepath.Join(root, "notes.txt"), "staged\n")
+	runGit(t, root, "add", "notes.txt")
+	ev := watcher.Event{Op: watcher.OpGit, Repo: filepath.Base(root), Git: watcher.GitOperation{Kind: watcher.GitIndex}}
+	m.Update(GitOperationMsg(ev))
+	m.Update(loadRepoEntries(d, ev.Repo)())
+
+	if !hasEntry(m.entries, edited) {
+		t.Errorf("entries %q lost the submodule file after a parent index change", entryNames(dir, m.entries))
+	}
+	if !hasEntry(m.entries, filepath.Join(root, "notes.txt")) {
+		t.Errorf("entries %q lack the staged parent file", entryNames(dir, m.entries))
+	}
+}
+
+func newTestModel(d differ.Differ) Model {
+	return New(nil, d, 200, "", nil, nil, "", nil, nil, DefaultKeyMap(), &config.Config{Context: differ.DefaultContext})
+}
+
+func initRepo(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "init", "--quiet")
+	writeTestFile(t, filepath.Join(dir, "README"), "readme\n")
+	runGit(t, dir, "add", "README")
+	runGit(t, dir, "commit", "--quiet", "-m", "Initial commit")
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasEntry(entries []types.DiffEntry, path string) bool {
+	for _, e := range entries {
+		if e.FilePath == path {
+			return true
+		}
+	}
+	return false
+}
+
+// entryNames returns the entries' paths relative to dir, sorted.
+func entryNames(dir string, entries []types.DiffEntry) []string {
+	var names []string
+	for _, e := range entries {
+		name := e.FilePath
+		if rel, err := filepath.Rel(dir, name); err == nil && filepath.IsAbs(name) {
+			name = rel
+		}
+		names = append(names, name)
+	}
+	return sorted(names)
+}
+
+func sorted(s []string) []string {
+	s = append([]string(nil), s...)
+	sort.Strings(s)
+	return s
+}
@@ -2,35 +2,45 @@
 // This includes keybindings documentation and visual styling for the help overlay.
 package model
 
-import "github.com/charmbracelet/lipgloss"
-
-// helpStyle defines the visual appearance of the help overlay using a
-// purple-themed color scheme that matches vibewatch's aesthetic.
-// Colors use hex codes: background (#282A36), foreground (#F8F8F2), border (#BD93F9)
-var helpStyle = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("#F8F8F2")).
-	Background(lipgloss.Color("#282A36")).
-	Padding(1, 2).
-	Border(lipgloss.RoundedBorder()).
-	BorderForeground(lipgloss.Color("#BD93F9"))
-
-func renderHelp() string {
-	content := "  Keybindings:\n" +
-		"  ───────────────────────────────\n" +
-		"  Tab            Next repo tab\n" +
-		"  Shift+Tab      Previous repo tab\n" +
-		"  1-9            Jump to tab by number\n" +
-		"  j / ↓          Scroll down\n" +
-		"  k / ↑          Scroll up\n" +
-		"  g / Home       Go to top\n" +
-		"  G / End        Go to bottom\n" +
-		"  p              Pause / Resume\n" +
-		"  c              Clear all entries\n" +
-		"  t              Toggle file visibility\n" +
-		"  T              Toggle first visible file\n" +
-		"  U              Untoggle file visibility\n" +
-		"  ?              Toggle this help\n" +
-		"  q / Ctrl+C     Quit"
-
-	return helpStyle.Render(content)
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpStyle is the frame of the help and other overlays; ApplyTheme sets
+// its colors.
+var helpStyle lipgloss.Style
+
+// helpKeyWidth is the width of the key column in the help overlay.
+const helpKeyWidth = 15
+
+// mouseHelp documents the mouse actions, which are not remappable.
+var mouseHelp = [][2]string{
+	{"Click", "Select entry / tab, ▾ toggles diff"},
+	{"Wheel", "Scroll entries"},
+}
+
+// renderHelp lists the bindings of keys, so the help always shows the keys
+// actually in effect.
+func renderHelp(keys KeyMap) string {
+	var b strings.Builder
+	b.WriteString("  Keybindings:\n")
+	b.WriteString("  ───────────────────────────────")
+	for _, nb := range keys.bindings() {
+		h := nb.binding.Help()
+		writeHelpLine(&b, h.Key, h.Desc)
+	}
+	for _, mh := range mouseHelp {
+		writeHelpLine(&b, mh[0], mh[1])
+	}
+
+	return helpStyle.Render(b.String())
+}
+
+// writeHelpLine writes one help row, padding keys by display width since
+// labels like "↑ / k" contain multi-byte runes.
+func writeHelpLine(b *strings.Builder, keys, desc string) {
+	pad := max(helpKeyWidth-lipgloss.Width(keys), 1)
+	b.WriteString("\n  " + keys + strings.Repeat(" ", pad) + desc)
 }
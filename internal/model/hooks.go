@@ -0,0 +1,113 @@
+// hooks.go renders the results of user-configured hooks: a badge on each
+// entry whose change triggered a hook, a summary in the status bar, and an
+// overlay with the last output of every hook.
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/hooks"
+)
+
+// hookOutputLines is the number of trailing output lines shown per hook in the overlay.
+const hookOutputLines = 15
+
+func waitForHookResult(ch <-chan hooks.Result) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		res, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return HookResultMsg(res)
+	}
+}
+
+// recordHookResult stores the latest run of a hook, keeping first-seen order.
+func (m *Model) recordHookResult(res hooks.Result) {
+	if _, exists := m.hookRuns[res.Hook]; !exists {
+		m.hookOrder = append(m.hookOrder, res.Hook)
+	}
+	m.hookRuns[res.Hook] = res
+}
+
+func hookBadge(res hooks.Result) string {
+	switch res.Status {
+	case hooks.StatusRunning:
+		return HookRunningStyle.Render("… " + res.Hook)
+	case hooks.StatusFailed:
+		return HookFailedStyle.Render("✗ " + res.Hook)
+	default:
+		return HookPassedStyle.Render("✓ " + res.Hook)
+	}
+}
+
+// renderHookBadges returns the badges of hooks whose last run included the file.
+func (m *Model) renderHookBadges(filePath string) string {
+	var badges []string
+	for _, name := range m.hookOrder {
+		res := m.hookRuns[name]
+		for _, f := range res.Files {
+			if f == filePath {
+				badges = append(badges, hookBadge(res))
+				break
+			}
+		}
+	}
+	if len(badges) == 0 {
+		return ""
+	}
+	return "  " + strings.Join(badges, " ")
+}
+
+// renderHookSummary returns a short status-bar summary of all hook runs.
+func (m *Model) renderHookSummary() string {
+	if len(m.hookOrder) == 0 {
+		return ""
+	}
+	var badges []string
+	for _, name := range m.hookOrder {
+		badges = append(badges, hookBadge(m.hookRuns[name]))
+	}
+	return "  " + strings.Join(badges, " ")
+}
+
+// renderHookOutput renders the overlay with the latest output of every hook.
+func (m *Model) renderHookOutput() string {
+	var b strings.Builder
+	b.WriteString("  Hooks:\n")
+	b.WriteString("  ───────────────────────────────\n")
+	if len(m.hookOrder) == 0 {
+		b.WriteString("  No hooks have run yet")
+		return helpStyle.Render(b.String())
+	}
+	for i, name := range m.hookOrder {
+		res := m.hookRuns[name]
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		line := "  " + hookBadge(res)
+		if res.Status != hooks.StatusRunning {
+			line += fmt.Sprintf("  %s  %s", res.Started.Format("15:04:05"), res.Duration.Round(100*time.Millisecond))
+		}
+		b.WriteString(line + "\n")
+
+		output := strings.Split(res.Output, "\n")
+		if len(output) > hookOutputLines {
+			output = output[len(output)-hookOutputLines:]
+		}
+		for _, l := range output {
+			if l == "" {
+				continue
+			}
+			b.WriteString("    " + l + "\n")
+		}
+	}
+	return helpStyle.Render(strings.TrimRight(b.String(), "\n"))
+}
@@ -0,0 +1,80 @@
+// hunks.go tracks which hunk of the selected entry is current, so actions
+// like copying or opening the editor can target a single hunk.
+package model
+
+import (
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+)
+
+// currentHunkIndex returns the index of the current hunk in the selected entry.
+// The index resets to the first hunk whenever a different file is selected.
+func (m *Model) currentHunkIndex() int {
+	if m.hunkFile != m.selectedFilePath {
+		return 0
+	}
+	return m.selectedHunk
+}
+
+// currentHunk returns the current hunk of the selected entry.
+func (m *Model) currentHunk() (differ.Hunk, bool) {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return differ.Hunk{}, false
+	}
+	hunks := differ.ParseHunks(m.displayDiff(e))
+	idx := m.currentHunkIndex()
+	if idx >= len(hunks) {
+		return differ.Hunk{}, false
+	}
+	return hunks[idx], true
+}
+
+// fullHunk maps a hunk of the displayed diff to the hunk of the entry's full
+// diff that contains it. Review marks and collapsed hunks are keyed to the
+// full diff, so they hold whether or not the since-last-look diff is shown.
+// Both diffs number lines as in the current file, so hunks are matched by the
+// lines they change.
+func (m *Model) fullHunk(e types.DiffEntry, h differ.Hunk) (differ.Hunk, bool) {
+	if !m.hasSinceLook(e) {
+		return h, true
+	}
+	start, end := h.ChangedRange()
+	for _, full := range differ.ParseHunks(e.Diff) {
+		first := full.NewStart
+		last := max(first, full.NewStart+full.NewLines-1)
+		if start <= last && end >= first {
+			return full, true
+		}
+	}
+	return differ.Hunk{}, false
+}
+
+// currentFullHunk returns the full-diff hunk containing the current hunk.
+func (m *Model) currentFullHunk() (differ.Hunk, bool) {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return differ.Hunk{}, false
+	}
+	h, ok := m.currentHunk()
+	if !ok {
+		return differ.Hunk{}, false
+	}
+	return m.fullHunk(e, h)
+}
+
+// navigateHunks moves the current hunk within the selected entry, wrapping
+// around at either end.
+func (m *Model) navigateHunks(delta int) {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return
+	}
+	count := len(differ.ParseHunks(m.displayDiff(e)))
+	if count == 0 {
+		return
+	}
+	m.selectedHunk = (m.currentHunkIndex() + delta + count) % count
+	m.hunkFile = m.selectedFilePath
+	m.viewport.SetContent(m.renderEntries())
+}
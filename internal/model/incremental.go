@@ -0,0 +1,86 @@
+// incremental.go shows, for files the user has already looked at, only what
+// changed since they last looked, instead of the full diff against the index.
+package model
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+)
+
+// maxSnapshotSize is the largest file whose content is kept for incremental diffs.
+const maxSnapshotSize = 1 << 20
+
+// readSnapshot returns the current content of a file. Missing files read as empty.
+func readSnapshot(path string) ([]byte, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, os.IsNotExist(err)
+	}
+	if info.IsDir() || info.Size() > maxSnapshotSize {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// markSeen records the selected file's current content as what the user last
+// looked at, so later changes are shown relative to it.
+func (m *Model) markSeen() {
+	if m.selectedFilePath == "" {
+		return
+	}
+	if data, ok := readSnapshot(m.selectedFilePath); ok {
+		m.lastSeen[m.selectedFilePath] = data
+	} else {
+		delete(m.lastSeen, m.selectedFilePath)
+	}
+	delete(m.sinceLook, m.selectedFilePath)
+}
+
+// computeSinceLook diffs a changed file against the content last looked at.
+func (m *Model) computeSinceLook(filePath string) tea.Cmd {
+	seen, ok := m.lastSeen[filePath]
+	if !ok {
+		return nil
+	}
+	name := m.relPath(filePath)
+	context := m.contextFor(filePath)
+	return func() tea.Msg {
+		current, ok := readSnapshot(filePath)
+		if !ok {
+			return nil
+		}
+		diff, err := differ.DiffContents(name, seen, current, context)
+		if err != nil {
+			logMessage(fmt.Sprintf("Model: Error computing incremental diff for %s: %v", filePath, err))
+			return nil
+		}
+		return SinceLookDiffMsg{FilePath: filePath, Diff: diff}
+	}
+}
+
+// hasSinceLook reports whether the entry is shown as an incremental diff.
+func (m *Model) hasSinceLook(e types.DiffEntry) bool {
+	if m.showFullDiff {
+		return false
+	}
+	_, ok := m.sinceLook[e.FilePath]
+	return ok
+}
+
+// displayDiff returns the diff shown for an entry: the changes since the user
+// last looked at it when available, otherwise the full diff.
+func (m *Model) displayDiff(e types.DiffEntry) string {
+	if m.hasSinceLook(e) {
+		return m.sinceLook[e.FilePath]
+	}
+	return e.Diff
+}
@@ -0,0 +1,303 @@
+// keys.go defines vibewatch's key bindings. The keymap drives both Update and
+// the help overlay, and any binding can be remapped from the config file.
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap holds every key binding of the interactive view.
+type KeyMap struct {
+	Up           key.Binding
+	Down         key.Binding
+	Top          key.Binding
+	Bottom       key.Binding
+	NextTab      key.Binding
+	PrevTab      key.Binding
+	JumpTab      key.Binding
+	Pause        key.Binding
+	Clear        key.Binding
+	ToggleDiff   key.Binding
+	HideAllDiffs key.Binding
+	ShowAllDiffs key.Binding
+	Hooks        key.Binding
+	Checks       key.Binding
+	FailedCheck  key.Binding
+	Editor       key.Binding
+	EditorURI    key.Binding
+	NextHunk     key.Binding
+	PrevHunk     key.Binding
+	CopyHunk     key.Binding
+	CopyDiff     key.Binding
+	CopyPath     key.Binding
+	Comment      key.Binding
+	CommentLine  key.Binding
+	Feedback     key.Binding
+	ClearFeed    key.Binding
+	ReviewFile   key.Binding
+	ReviewHunk   key.Binding
+	Unreviewed   key.Binding
+	SinceLook    key.Binding
+	BaseRef      key.Binding
+	Filter       key.Binding
+	Expand       key.Binding
+	CollapseHunk key.Binding
+	MoreContext  key.Binding
+	LessContext  key.Binding
+	Tree         key.Binding
+	TreeFold     key.Binding
+	TreeUnfold   key.Binding
+	TreeOpen     key.Binding
+	Stats        key.Binding
+	Search       key.Binding
+	NextMatch    key.Binding
+	PrevMatch    key.Binding
+	ClearSearch  key.Binding
+	Help         key.Binding
+	Quit         key.Binding
+}
+
+// namedBinding pairs a binding with the name used for it in the config file.
+type namedBinding struct {
+	name    string
+	binding *key.Binding
+}
+
+// DefaultKeyMap returns the built-in key bindings.
+func DefaultKeyMap() KeyMap {
+	bind := func(desc string, keys ...string) key.Binding {
+		return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keysLabel(keys), desc))
+	}
+	return KeyMap{
+		Up:           bind("Previous file", "up", "k"),
+		Down:         bind("Next file", "down", "j"),
+		Top:          bind("Go to top", "g", "home"),
+		Bottom:       bind("Go to bottom", "G", "end"),
+		NextTab:      bind("Next repo tab", "tab"),
+		PrevTab:      bind("Previous repo tab", "shift+tab"),
+		JumpTab:      bind("Jump to tab by number", "1", "2", "3", "4", "5", "6", "7", "8", "9"),
+		Pause:        bind("Pause / resume", "p"),
+		Clear:        bind("Clear all entries", "c"),
+		ToggleDiff:   bind("Hide / show selected diff", "t"),
+		HideAllDiffs: bind("Hide all diffs", "T"),
+		ShowAllDiffs: bind("Show all diffs", "U"),
+		Hooks:        bind("Toggle hook output", "o"),
+		Checks:       bind("Toggle checks panel", "C"),
+		FailedCheck:  bind("Jump to file of failed check", "f"),
+		Editor:       bind("Open file in $EDITOR", "e"),
+		EditorURI:    bind("Show editor URI", "E"),
+		NextHunk:     bind("Next hunk", "]"),
+		PrevHunk:     bind("Previous hunk", "["),
+		CopyHunk:     bind("Copy current hunk", "y"),
+		CopyDiff:     bind("Copy whole diff", "Y"),
+		CopyPath:     bind("Copy file path", "ctrl+y"),
+		Comment:      bind("Comment on current hunk", "a"),
+		CommentLine:  bind("Comment on a line of current hunk", "L"),
+		Feedback:     bind("Show collected feedback", "A"),
+		ClearFeed:    bind("Clear all feedback", "D"),
+		ReviewFile:   bind("Mark file reviewed", "r"),
+		ReviewHunk:   bind("Mark current hunk reviewed", "R"),
+		Unreviewed:   bind("Show only unreviewed changes", "u"),
+		SinceLook:    bind("Toggle since-last-look / full diffs", "i"),
+		BaseRef:      bind("Diff against a git ref", "B"),
+		Filter:       bind("Filter files by path or repo", "/"),
+		Expand:       bind("Expand / truncate long diff", "x"),
+		CollapseHunk: bind("Collapse / expand current hunk", "z"),
+		MoreContext:  bind("More context lines", "+", "="),
+		LessContext:  bind("Less context lines", "-"),
+		Tree:         bind("Toggle list / directory tree", "v"),
+		TreeFold:     bind("Tree: fold directory", "left", "h"),
+		TreeUnfold:   bind("Tree: unfold directory", "right", "l"),
+		TreeOpen:     bind("Tree: fold dir or open file", "enter", " "),
+		Stats:        bind("Toggle session statistics", "S"),
+		Search:       bind("Search diffs (+re / -re limits)", "s"),
+		NextMatch:    bind("Next match (or file)", "n"),
+		PrevMatch:    bind("Previous match (or file)", "N"),
+		ClearSearch:  bind("Clear search / filter", "esc"),
+		Help:         bind("Toggle this help", "?"),
+		Quit:         bind("Quit", "q", "ctrl+c"),
+	}
+}
+
+// NewKeyMap returns the default bindings with the overrides from the config
+// file applied. Overrides map a binding name (e.g. "next_hunk") to its keys.
+func NewKeyMap(overrides map[string][]string) (KeyMap, error) {
+	k := DefaultKeyMap()
+	byName := make(map[string]*key.Binding)
+	for _, nb := range k.bindings() {
+		byName[nb.name] = nb.binding
+	}
+
+	for name, keys := range overrides {
+		b, ok := byName[name]
+		if !ok {
+			return k, fmt.Errorf("unknown key binding %q (valid names: %s)", name, strings.Join(bindingNames(), ", "))
+		}
+		if len(keys) == 0 {
+			return k, fmt.Errorf("key binding %q has no keys", name)
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(keysLabel(keys), b.Help().Desc)
+	}
+
+	return k, k.checkConflicts()
+}
+
+// bindings lists the bindings in help order with their config names.
+func (k *KeyMap) bindings() []namedBinding {
+	return []namedBinding{
+		{"up", &k.Up},
+		{"down", &k.Down},
+		{"top", &k.Top},
+		{"bottom", &k.Bottom},
+		{"next_tab", &k.NextTab},
+		{"prev_tab", &k.PrevTab},
+		{"jump_tab", &k.JumpTab},
+		{"pause", &k.Pause},
+		{"clear", &k.Clear},
+		{"toggle_diff", &k.ToggleDiff},
+		{"hide_all_diffs", &k.HideAllDiffs},
+		{"show_all_diffs", &k.ShowAllDiffs},
+		{"hooks", &k.Hooks},
+		{"checks", &k.Checks},
+		{"failed_check", &k.FailedCheck},
+		{"editor", &k.Editor},
+		{"editor_uri", &k.EditorURI},
+		{"next_hunk", &k.NextHunk},
+		{"prev_hunk", &k.PrevHunk},
+		{"copy_hunk", &k.CopyHunk},
+		{"copy_diff", &k.CopyDiff},
+		{"copy_path", &k.CopyPath},
+		{"comment", &k.Comment},
+		{"comment_line", &k.CommentLine},
+		{"feedback", &k.Feedback},
+		{"clear_feedback", &k.ClearFeed},
+		{"review_file", &k.ReviewFile},
+		{"review_hunk", &k.ReviewHunk},
+		{"unreviewed_only", &k.Unreviewed},
+		{"since_look", &k.SinceLook},
+		{"base_ref", &k.BaseRef},
+		{"filter", &k.Filter},
+		{"expand", &k.Expand},
+		{"collapse_hunk", &k.CollapseHunk},
+		{"more_context", &k.MoreContext},
+		{"less_context", &k.LessContext},
+		{"tree", &k.Tree},
+		{"tree_fold", &k.TreeFold},
+		{"tree_unfold", &k.TreeUnfold},
+		{"tree_open", &k.TreeOpen},
+		{"stats", &k.Stats},
+		{"search", &k.Search},
+		{"next_match", &k.NextMatch},
+		{"prev_match", &k.PrevMatch},
+		{"clear_search", &k.ClearSearch},
+		{"help", &k.Help},
+		{"quit", &k.Quit},
+	}
+}
+
+// checkConflicts reports a key bound to two actions.
+func (k *KeyMap) checkConflicts() error {
+	owner := make(map[string]string)
+	for _, nb := range k.bindings() {
+		for _, kk := range nb.binding.Keys() {
+			if other, ok := owner[kk]; ok {
+				return fmt.Errorf("key %q is bound to both %s and %s", kk, other, nb.name)
+			}
+			owner[kk] = nb.name
+		}
+	}
+	return nil
+}
+
+// keyNames are the display names of special keys.
+var keyNames = map[string]string{
+	"up":        "↑",
+	"down":      "↓",
+	"left":      "←",
+	"right":     "→",
+	" ":         "Space",
+	"tab":       "Tab",
+	"shift+tab": "Shift+Tab",
+	"enter":     "Enter",
+	"esc":       "Esc",
+	"home":      "Home",
+	"end":       "End",
+	"pgup":      "PgUp",
+	"pgdown":    "PgDn",
+}
+
+// keyLabel returns the display name of a key, e.g. "Ctrl+Y" for "ctrl+y".
+func keyLabel(k string) string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	if rest, ok := strings.CutPrefix(k, "ctrl+"); ok {
+		return "Ctrl+" + strings.ToUpper(rest)
+	}
+	if rest, ok := strings.CutPrefix(k, "alt+"); ok {
+		return "Alt+" + keyLabel(rest)
+	}
+	return k
+}
+
+// keysLabel returns the help label for a list of keys. A run of digits is
+// shown as a range, e.g. "1-9".
+func keysLabel(keys []string) string {
+	if len(keys) > 2 && isDigitRun(keys) {
+		return keys[0] + "-" + keys[len(keys)-1]
+	}
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = keyLabel(k)
+	}
+	return strings.Join(labels, " / ")
+}
+
+// isDigitRun reports whether keys are consecutive single digits.
+func isDigitRun(keys []string) bool {
+	for i, k := range keys {
+		if len(k) != 1 || !unicode.IsDigit(rune(k[0])) {
+			return false
+		}
+		if i > 0 && k[0] != keys[i-1][0]+1 {
+			return false
+		}
+	}
+	return true
+}
+
+// keyHint returns the first key of a binding for hints such as "press t".
+func keyHint(b key.Binding) string {
+	if keys := b.Keys(); len(keys) > 0 {
+		return keyLabel(keys[0])
+	}
+	return ""
+}
+
+// keyIndex returns the position of the pressed key within a binding's keys,
+// used by bindings like JumpTab whose keys select a numbered target.
+func keyIndex(msg string, b key.Binding) int {
+	for i, k := range b.Keys() {
+		if k == msg {
+			return i
+		}
+	}
+	return -1
+}
+
+// bindingNames returns the names that can be remapped in the config file.
+func bindingNames() []string {
+	k := DefaultKeyMap()
+	var names []string
+	for _, nb := range k.bindings() {
+		names = append(names, nb.name)
+	}
+	sort.Strings(names)
+	return names
+}
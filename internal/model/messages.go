@@ -1,10 +1,51 @@
 package model
 
-import "codeberg.org/devcarlosmolero/vibewatch/internal/types"
+import (
+	"codeberg.org/devcarlosmolero/vibewatch/internal/feedback"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/hooks"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/watcher"
+)
 
 // FileChangedMsg is sent when a file change is detected and diffed.
 type FileChangedMsg types.DiffEntry
 
+// GitOperationMsg is sent when a git operation changed a repository's state.
+type GitOperationMsg watcher.Event
+
+// RepoChangedMsg is sent when a repository under the watched directory was
+// added or removed in multi-repo mode. Name is "" when nothing changed.
+type RepoChangedMsg struct {
+	Name    string
+	Branch  string
+	Removed bool
+}
+
+// CommitMsg carries a commit made during the session.
+type CommitMsg types.DiffEntry
+
+// RepoEntriesMsg carries the reloaded dirty files of one repository, or of
+// all of them when Repo is "".
+type RepoEntriesMsg struct {
+	Repo    string
+	Entries []types.DiffEntry
+}
+
+// BaseChangedMsg carries the dirty files reloaded after the base ref changed.
+// Err is set when the ref did not resolve in some or all repositories.
+type BaseChangedMsg struct {
+	Base    string
+	Entries []types.DiffEntry
+	Err     error
+}
+
+// FeedbackLoadedMsg carries the comments left in the feedback file by an
+// earlier session.
+type FeedbackLoadedMsg struct {
+	Comments []feedback.Comment
+	Err      error
+}
+
 // InitialEntriesMsg carries pre-existing dirty files found at startup.
 type InitialEntriesMsg []types.DiffEntry
 
@@ -14,8 +55,33 @@ type ToggleFileMsg string
 // ShowAllFilesMsg is sent when all files should be made visible.
 type ShowAllFilesMsg bool
 
-// UntoggleFileMsg is sent when a specific file should be made visible.
-type UntoggleFileMsg string
-
 // UpdateBranchesMsg is sent when branch information should be refreshed.
 type UpdateBranchesMsg map[string]string
+
+// WatcherErrorMsg is sent when the event source reports a problem.
+type WatcherErrorMsg struct {
+	Err error
+}
+
+// HookResultMsg is sent when a hook starts or finishes running.
+type HookResultMsg hooks.Result
+
+// CheckResultMsg is sent when a check starts or finishes running.
+type CheckResultMsg hooks.Result
+
+// EditorFinishedMsg is sent when the external editor exits.
+type EditorFinishedMsg struct {
+	Err error
+}
+
+// clearStatusMsg clears the status bar message with the matching sequence number.
+type clearStatusMsg int
+
+// SinceLookDiffMsg carries the diff of a file since the user last looked at it.
+type SinceLookDiffMsg struct {
+	FilePath string
+	Diff     string
+}
+
+// ContextDiffMsg carries a diff re-fetched with a different number of context lines.
+type ContextDiffMsg types.DiffEntry
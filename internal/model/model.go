@@ -1,20 +1,29 @@
 package model
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"codeberg.org/devcarlosmolero/vibewatch/internal/config"
 	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/feedback"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/hooks"
 	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/watcher"
 )
 
 // getLogDir returns the appropriate directory for log files
@@ -42,14 +51,17 @@ func getLogDir() string {
 	return logDir
 }
 
-const maxDiffLines = 100
+const (
+	maxDiffLines = 100
+	flashTimeout = 5 * time.Second
+)
 
 type Model struct {
 	entries           []types.DiffEntry
 	viewport          viewport.Model
 	width             int
 	height            int
-	changes           <-chan string
+	source            watcher.Source
 	differ            differ.Differ
 	maxEntries        int
 	paused            bool
@@ -65,24 +77,90 @@ type Model struct {
 	showHiddenCount   int
 	selectedFileIndex int
 	selectedFilePath  string
+	hookResults       <-chan hooks.Result
+	hookRuns          map[string]hooks.Result
+	hookOrder         []string
+	showHooks         bool
+	checkResults      <-chan hooks.Result
+	checkRuns         map[string]hooks.Result
+	checkOrder        []string
+	showChecks        bool
+	checkLinkIndex    int
+	editorURITemplate string
+	statusMessage     string
+	statusSeq         int
+	selectedHunk      int
+	hunkFile          string
+	prompt            textinput.Model
+	promptKind        promptKind
+	pendingComment    feedback.Comment
+	comments          []feedback.Comment
+	feedbackPath      string
+	showFeedback      bool
+	reviewedFiles     map[string]uint64
+	reviewedHunks     map[string]map[uint64]bool
+	unreviewedOnly    bool
+	lastSeen          map[string][]byte
+	sinceLook         map[string]string
+	showFullDiff      bool
+	fileFilter        string
+	base              string // ref diffs are taken against, "" for the index
+	entryOffsets      []int
+	tabEnds           []int // right edge of each tab, for mouse clicks
+	keys              KeyMap
+	searchQuery       string
+	searchRe          *regexp.Regexp
+	searchScope       searchScope
+	searchHits        []int
+	searchIndex       int
+	pendingHits       []int
+	viewMode          viewMode
+	treeCursor        int
+	collapsedDirs     map[string]bool
+	stats             sessionStats
+	expandedEntries   map[string]bool
+	collapsedHunks    map[string]map[uint64]bool
+	contextLines      map[string]int
+	defaultContext    int
+	terminal          io.Writer // where Bubble Tea renders, for OSC52 copies
 }
 
-func New(changes <-chan string, d differ.Differ, maxEntries int, dir string, repoNames []string, branches map[string]string, branch string) Model {
+func New(source watcher.Source, d differ.Differ, maxEntries int, dir string, repoNames []string, branches map[string]string, branch string, hookResults, checkResults <-chan hooks.Result, keys KeyMap, cfg *config.Config) Model {
 	var tabs []string
 	if len(repoNames) > 1 {
 		tabs = []string{"All"}
 		tabs = append(tabs, repoNames...)
 	}
 	return Model{
-		changes:         changes,
-		differ:          d,
-		maxEntries:      maxEntries,
-		dir:             dir,
-		tabs:            tabs,
-		branches:        branches,
-		branch:          branch,
-		visibleFiles:    make(map[string]bool),
-		showHiddenCount: 0,
+		source:            source,
+		differ:            d,
+		maxEntries:        maxEntries,
+		dir:               dir,
+		tabs:              tabs,
+		branches:          branches,
+		branch:            branch,
+		base:              d.Base(),
+		visibleFiles:      make(map[string]bool),
+		showHiddenCount:   0,
+		hookResults:       hookResults,
+		hookRuns:          make(map[string]hooks.Result),
+		checkResults:      checkResults,
+		checkRuns:         make(map[string]hooks.Result),
+		showChecks:        true,
+		editorURITemplate: cfg.EditorURI,
+		feedbackPath:      cfg.FeedbackFile,
+		reviewedFiles:     make(map[string]uint64),
+		reviewedHunks:     make(map[string]map[uint64]bool),
+		lastSeen:          make(map[string][]byte),
+		sinceLook:         make(map[string]string),
+		collapsedDirs:     make(map[string]bool),
+		stats:             newSessionStats(),
+		expandedEntries:   make(map[string]bool),
+		collapsedHunks:    make(map[string]map[uint64]bool),
+		contextLines:      make(map[string]int),
+		defaultContext:    cfg.Context,
+		keys:              keys,
+		terminal:          os.Stdout,
 	}
 }
 
@@ -92,7 +170,11 @@ func (m *Model) Init() tea.Cmd {
 
 	return tea.Batch(
 		loadInitialEntries(m.differ),
-		waitForChange(m.changes, m.differ),
+		loadFeedback(m.feedbackPath),
+		waitForChange(m.source, m.differ),
+		waitForWatcherError(m.source),
+		waitForHookResult(m.hookResults),
+		waitForCheckResult(m.checkResults),
 	)
 }
 
@@ -101,55 +183,158 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
+		if m.promptKind != promptNone {
+			return m.updatePrompt(msg)
+		}
+		if m.viewMode == viewTree && m.updateTree(msg) {
+			return m, nil
+		}
+		k := m.keys
+		switch {
+		case key.Matches(msg, k.Quit):
 			return m, tea.Quit
-		case "?":
+		case key.Matches(msg, k.Help):
 			m.showHelp = !m.showHelp
 			return m, nil
-		case "p":
+		case key.Matches(msg, k.Hooks):
+			m.showHooks = !m.showHooks
+			return m, nil
+		case key.Matches(msg, k.Checks):
+			m.showChecks = !m.showChecks
+			m.layout()
+			return m, nil
+		case key.Matches(msg, k.FailedCheck):
+			m.jumpToCheckFile()
+			return m, nil
+		case key.Matches(msg, k.Editor):
+			return m, m.openInEditor()
+		case key.Matches(msg, k.EditorURI):
+			return m, m.showEditorURI()
+		case key.Matches(msg, k.NextHunk):
+			m.navigateHunks(1)
+			return m, nil
+		case key.Matches(msg, k.PrevHunk):
+			m.navigateHunks(-1)
+			return m, nil
+		case key.Matches(msg, k.CopyHunk):
+			return m, m.copyCurrentHunk()
+		case key.Matches(msg, k.CopyDiff):
+			return m, m.copySelectedDiff()
+		case key.Matches(msg, k.CopyPath):
+			return m, m.copySelectedPath()
+		case key.Matches(msg, k.Comment):
+			return m, m.startComment()
+		case key.Matches(msg, k.CommentLine):
+			return m, m.startLineComment()
+		case key.Matches(msg, k.Feedback):
+			m.showFeedback = !m.showFeedback
+			return m, nil
+		case key.Matches(msg, k.ClearFeed):
+			return m, m.clearComments()
+		case key.Matches(msg, k.ReviewFile):
+			return m, m.toggleFileReviewed()
+		case key.Matches(msg, k.ReviewHunk):
+			return m, m.toggleHunkReviewed()
+		case key.Matches(msg, k.SinceLook):
+			m.showFullDiff = !m.showFullDiff
+			m.viewport.SetContent(m.renderEntries())
+			if m.showFullDiff {
+				return m, m.flash("Showing full diffs")
+			}
+			return m, m.flash("Showing changes since last look")
+		case key.Matches(msg, k.BaseRef):
+			return m, m.openPrompt(promptBase, " diff against (empty for index): ", m.base)
+		case key.Matches(msg, k.Filter):
+			return m, m.openPrompt(promptFilter, " / ", m.fileFilter)
+		case key.Matches(msg, k.Expand):
+			return m, m.toggleExpanded()
+		case key.Matches(msg, k.CollapseHunk):
+			return m, m.toggleHunkCollapsed()
+		case key.Matches(msg, k.MoreContext):
+			return m, m.changeContext(1)
+		case key.Matches(msg, k.LessContext):
+			return m, m.changeContext(-1)
+		case key.Matches(msg, k.Tree):
+			m.toggleViewMode()
+			return m, nil
+		case key.Matches(msg, k.Stats):
+			if m.viewMode == viewStats {
+				m.viewMode = viewList
+				m.ensureSelectedFileVisible()
+			} else {
+				m.viewMode = viewStats
+				m.viewport.SetContent(m.renderEntries())
+				m.viewport.GotoTop()
+			}
+			return m, nil
+		case key.Matches(msg, k.Search):
+			return m, m.openPrompt(promptSearch, " search diffs (+re added, -re removed): ", m.searchQuery)
+		case key.Matches(msg, k.NextMatch):
+			if m.searchRe != nil {
+				m.jumpToSearchHit(1)
+				return m, nil
+			}
+			return m.navigateFiles(1)
+		case key.Matches(msg, k.PrevMatch):
+			if m.searchRe != nil {
+				m.jumpToSearchHit(-1)
+				return m, nil
+			}
+			return m.navigateFiles(-1)
+		case key.Matches(msg, k.ClearSearch):
+			if m.searchRe != nil {
+				m.setContentSearch("")
+			} else if m.fileFilter != "" {
+				m.setFileFilter("")
+			}
+			return m, nil
+		case key.Matches(msg, k.Unreviewed):
+			m.unreviewedOnly = !m.unreviewedOnly
+			m.syncSelection()
+			m.viewport.GotoTop()
+			return m, nil
+		case key.Matches(msg, k.Pause):
 			m.paused = !m.paused
 			return m, nil
-		case "c":
+		case key.Matches(msg, k.Clear):
 			m.entries = nil
 			m.viewport.SetContent(m.renderEntries())
 			return m, nil
-		case "g", "home":
+		case key.Matches(msg, k.Top):
 			m.viewport.GotoTop()
 			return m, nil
-		case "G", "end":
+		case key.Matches(msg, k.Bottom):
 			m.viewport.GotoBottom()
 			return m, nil
-		case "tab":
+		case key.Matches(msg, k.NextTab):
 			if len(m.tabs) > 0 {
 				m.activeTab = (m.activeTab + 1) % len(m.tabs)
 				m.viewport.SetContent(m.renderEntries())
 				m.viewport.GotoTop()
 			}
 			return m, nil
-		case "shift+tab":
+		case key.Matches(msg, k.PrevTab):
 			if len(m.tabs) > 0 {
 				m.activeTab = (m.activeTab - 1 + len(m.tabs)) % len(m.tabs)
 				m.viewport.SetContent(m.renderEntries())
 				m.viewport.GotoTop()
 			}
 			return m, nil
-		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-			idx := int(msg.String()[0] - '1')
+		case key.Matches(msg, k.JumpTab):
+			idx := keyIndex(msg.String(), k.JumpTab)
 			if len(m.tabs) > 0 && idx < len(m.tabs) {
 				m.activeTab = idx
 				m.viewport.SetContent(m.renderEntries())
 				m.viewport.GotoTop()
 			}
 			return m, nil
-		case "t":
+		case key.Matches(msg, k.ToggleDiff):
 			if len(m.entries) > 0 {
 				filePath := m.selectedFilePath
 				if filePath == "" {
 					filtered := m.filteredEntries()
 					if len(filtered) > 0 {
-						m.selectedFileIndex = 0
-						m.selectedFilePath = filtered[0].FilePath
+						m.selectEntry(0, filtered[0].FilePath)
 						filePath = filtered[0].FilePath
 					}
 				}
@@ -158,64 +343,33 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			return m, nil
-		case "T":
-			if len(m.entries) > 0 {
-				filePath := m.selectedFilePath
-				if filePath == "" {
-					for _, entry := range m.entries {
-						if m.isFileVisible(entry.FilePath) {
-							filePath = entry.FilePath
-							break
-						}
-					}
-				}
-				if filePath != "" {
-					return m, m.toggleFileVisibility(filePath)
-				}
-			}
+		case key.Matches(msg, k.HideAllDiffs):
+			m.setAllDiffsVisible(false)
 			return m, nil
-		case "U":
-			if len(m.entries) > 0 {
-				filePath := m.selectedFilePath
-				if filePath == "" {
-					filtered := m.filteredEntries()
-					if len(filtered) > 0 {
-						m.selectedFileIndex = 0
-						m.selectedFilePath = filtered[0].FilePath
-						filePath = filtered[0].FilePath
-					}
-				}
-				if filePath != "" && !m.isDiffVisible(filePath) {
-					return m, m.untoggleFileVisibility(filePath)
-				}
-			}
+		case key.Matches(msg, k.ShowAllDiffs):
+			m.setAllDiffsVisible(true)
 			return m, nil
-		case "up", "k":
+		case key.Matches(msg, k.Up):
 			return m.navigateFiles(-1)
-		case "down", "j":
+		case key.Matches(msg, k.Down):
 			return m.navigateFiles(1)
-
 		}
 
+	case tea.MouseMsg:
+		return m, m.updateMouse(msg)
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		headerHeight := 1
-		tabHeight := 0
-		if len(m.tabs) > 0 {
-			tabHeight = 1
-		}
-		statusHeight := 1
-		vpHeight := m.height - headerHeight - tabHeight - statusHeight
+		m.layout()
+		return m, nil
 
-		if !m.ready {
-			m.viewport = viewport.New(m.width, vpHeight)
-			m.viewport.SetContent(m.renderEntries())
-			m.ready = true
-		} else {
-			m.viewport.Width = m.width
-			m.viewport.Height = vpHeight
+	case FeedbackLoadedMsg:
+		if msg.Err != nil {
+			return m, m.flash("Loading feedback failed: " + msg.Err.Error())
 		}
+		m.comments = append(msg.Comments, m.comments...)
+		m.viewport.SetContent(m.renderEntries())
 		return m, nil
 
 	case InitialEntriesMsg:
@@ -224,25 +378,65 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if len(m.entries) > m.maxEntries {
 			m.entries = m.entries[:m.maxEntries]
 		}
+		for _, e := range m.entries {
+			m.pruneReviewed(e)
+		}
 		m.viewport.SetContent(m.renderEntries())
 		return m, nil
 
-	case FileChangedMsg:
-		entry := types.DiffEntry(msg)
+	case GitOperationMsg:
+		logMessage(fmt.Sprintf("Model: Git operation %s in %q, refreshing its files and branches", msg.Git.Kind, msg.Repo))
+		cmds = append(cmds, loadRepoEntries(m.differ, msg.Repo))
+		cmds = append(cmds, updateBranches(m.differ))
+		if msg.Git.Kind == watcher.GitCommit || msg.Git.Kind == watcher.GitMerge {
+			cmds = append(cmds, loadCommit(m.differ, watcher.Event(msg)))
+		}
+		cmds = append(cmds, waitForChange(m.source, m.differ))
+		if text := describeGitOperation(watcher.Event(msg)); text != "" {
+			cmds = append(cmds, m.flash(text))
+		}
+		return m, tea.Batch(cmds...)
 
-		if entry.FilePath == "__GIT_OPERATION__" {
-			logMessage("Model: Git operation detected, refreshing all files and branches")
-			cmds = append(cmds, loadInitialEntries(m.differ))
-			cmds = append(cmds, updateBranches(m.differ))
-			cmds = append(cmds, waitForChange(m.changes, m.differ))
-			return m, tea.Batch(cmds...)
+	case WatcherErrorMsg:
+		logMessage(fmt.Sprintf("Model: Watcher error: %v", msg.Err))
+		cmds = append(cmds, m.flash("Watcher: "+msg.Err.Error()))
+		if errors.Is(msg.Err, watcher.ErrOverflow) {
+			cmds = append(cmds, rescanEntries(m.differ))
+		}
+		cmds = append(cmds, waitForWatcherError(m.source))
+		return m, tea.Batch(cmds...)
+
+	case BaseChangedMsg:
+		return m, m.applyBase(msg)
+
+	case RepoChangedMsg:
+		cmds = append(cmds, m.applyRepoChange(msg))
+		cmds = append(cmds, waitForChange(m.source, m.differ))
+		return m, tea.Batch(cmds...)
+
+	case CommitMsg:
+		m.addCommit(types.DiffEntry(msg))
+		m.viewport.SetContent(m.renderEntries())
+		if !m.paused {
+			m.viewport.GotoTop()
 		}
+		return m, nil
+
+	case RepoEntriesMsg:
+		m.replaceRepoEntries(msg.Repo, msg.Entries)
+		m.syncSelection()
+		m.viewport.SetContent(m.renderEntries())
+		return m, nil
+
+	case FileChangedMsg:
+		entry := types.DiffEntry(msg)
 
 		if entry.Diff == "" && entry.Error == "" && !entry.IsNew {
 			logMessage(fmt.Sprintf("Model: Removing committed file: %s", entry.FilePath))
 			m.entries = removeEntriesForFile(m.entries, entry.FilePath)
+			delete(m.sinceLook, entry.FilePath)
 			m.viewport.SetContent(m.renderEntries())
-			cmds = append(cmds, waitForChange(m.changes, m.differ))
+			cmds = append(cmds, waitForChange(m.source, m.differ))
 			return m, tea.Batch(cmds...)
 		}
 
@@ -250,6 +444,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			logMessage(fmt.Sprintf("Model: Error getting diff for %s: %s", entry.FilePath, entry.Error))
 		}
 
+		m.stats.record(entry)
+		m.pruneReviewed(entry)
 		m.entries = removeEntriesForFile(m.entries, entry.FilePath)
 		m.entries = append([]types.DiffEntry{entry}, m.entries...)
 		if len(m.entries) > m.maxEntries {
@@ -259,9 +455,20 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if !m.paused {
 			m.viewport.GotoTop()
 		}
-		cmds = append(cmds, waitForChange(m.changes, m.differ))
+		cmds = append(cmds, m.computeSinceLook(entry.FilePath))
+		cmds = append(cmds, m.refetchContext(entry.FilePath))
+		cmds = append(cmds, waitForChange(m.source, m.differ))
 		return m, tea.Batch(cmds...)
 
+	case ContextDiffMsg:
+		m.replaceDiff(types.DiffEntry(msg))
+		return m, nil
+
+	case SinceLookDiffMsg:
+		m.sinceLook[msg.FilePath] = msg.Diff
+		m.viewport.SetContent(m.renderEntries())
+		return m, nil
+
 	case ToggleFileMsg:
 		filePath := string(msg)
 		m.visibleFilesMu.Lock()
@@ -282,19 +489,24 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.ensureSelectedFileVisible()
 		}
 		return m, nil
-	case UntoggleFileMsg:
-		filePath := string(msg)
-		m.visibleFilesMu.Lock()
-		if _, exists := m.visibleFiles[filePath]; exists {
-			m.visibleFiles[filePath] = true
-			m.showHiddenCount--
-		}
-		m.visibleFilesMu.Unlock()
+	case HookResultMsg:
+		m.recordHookResult(hooks.Result(msg))
 		m.viewport.SetContent(m.renderEntries())
-		if filePath == m.selectedFilePath {
-			m.ensureSelectedFileVisible()
+		return m, waitForHookResult(m.hookResults)
+	case EditorFinishedMsg:
+		if msg.Err != nil {
+			return m, m.flash("Editor: " + msg.Err.Error())
+		}
+		return m, nil
+	case clearStatusMsg:
+		if int(msg) == m.statusSeq {
+			m.statusMessage = ""
 		}
 		return m, nil
+	case CheckResultMsg:
+		m.recordCheckResult(hooks.Result(msg))
+		m.layout()
+		return m, waitForCheckResult(m.checkResults)
 	case UpdateBranchesMsg:
 		newBranches := map[string]string(msg)
 		m.branches = newBranches
@@ -325,9 +537,17 @@ func (m *Model) View() string {
 
 	// Header
 	headerText := fmt.Sprintf(" vibewatch — watching %s", m.dir)
+	if ws := m.source.Stats(); ws.Backend == watcher.BackendPoll {
+		headerText += " (polling)"
+	} else if ws.Polled > 0 {
+		headerText += fmt.Sprintf(" (polling %d dirs)", ws.Polled)
+	}
 	if m.branch != "" {
 		headerText += "  " + BranchStyle.Render(m.branch)
 	}
+	if m.base != "" {
+		headerText += "  " + BranchStyle.Render("vs "+m.base)
+	}
 	header := HeaderStyle.Width(m.width).Render(headerText)
 
 	// Status bar
@@ -335,27 +555,61 @@ func (m *Model) View() string {
 	totalVisible := len(filtered)
 	totalFiles := len(m.entries)
 	status := fmt.Sprintf(" %d changes", totalVisible)
-	if len(m.tabs) > 0 && totalVisible != totalFiles {
+	if (len(m.tabs) > 0 || m.fileFilter != "") && totalVisible != totalFiles {
 		status += fmt.Sprintf(" (of %d total)", totalFiles)
 	}
+	if m.fileFilter != "" {
+		status += "  " + FlashStyle.Render(fmt.Sprintf("/%s", m.fileFilter)) + fmt.Sprintf(" (%s clears)", keyHint(m.keys.ClearSearch))
+	}
+	if m.searchRe != nil {
+		status += "  " + FlashStyle.Render(fmt.Sprintf("search %s", m.searchQuery))
+		if m.searchIndex >= 0 && m.searchIndex < len(m.searchHits) {
+			status += fmt.Sprintf(" %d/%d", m.searchIndex+1, len(m.searchHits))
+		} else {
+			status += fmt.Sprintf(" %d matches", len(m.searchHits))
+		}
+	}
 	if m.showHiddenCount > 0 {
 		status += fmt.Sprintf("  %d hidden", m.showHiddenCount)
 	}
+	if reviewed := m.reviewedCount(m.entries); reviewed > 0 || m.unreviewedOnly {
+		status += fmt.Sprintf("  %d/%d reviewed", reviewed, totalFiles)
+		if m.unreviewedOnly {
+			status += " (showing unreviewed)"
+		}
+	}
 	if m.paused {
 		status += "  " + PausedStyle.Render("[PAUSED]")
 	}
+	status += m.renderHookSummary()
+	if m.statusMessage != "" {
+		status += "  " + FlashStyle.Render(m.statusMessage)
+	}
 	if len(m.tabs) > 0 {
-		status += "  tab switch"
+		status += fmt.Sprintf("  %s switch", keyHint(m.keys.NextTab))
+	}
+	status += fmt.Sprintf("  %s toggle  %s help  %s quit", keyHint(m.keys.ToggleDiff), keyHint(m.keys.Help), keyHint(m.keys.Quit))
+	statusBar := StatusBarStyle.Width(m.width).MaxHeight(1).Render(status)
+	if m.promptKind != promptNone {
+		statusBar = StatusBarStyle.Width(m.width).Render(m.prompt.View())
 	}
-	status += "  t toggle  ? help  q quit"
-	statusBar := StatusBarStyle.Width(m.width).Render(status)
 
 	// Help overlay
 	if m.showHelp {
-		helpText := renderHelp()
+		helpText := renderHelp(m.keys)
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, helpText)
 	}
 
+	// Feedback overlay
+	if m.showFeedback {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.renderFeedback())
+	}
+
+	// Hook output overlay
+	if m.showHooks {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.renderHookOutput())
+	}
+
 	mainContent := ""
 	if len(m.tabs) > 0 {
 		tabBar := m.renderTabs()
@@ -364,11 +618,54 @@ func (m *Model) View() string {
 		mainContent = header + "\n" + m.viewport.View()
 	}
 
+	if m.checksPanelHeight() > 0 {
+		mainContent += "\n" + m.renderChecks()
+	}
+
 	return mainContent + "\n" + statusBar
 }
 
+// flash shows a message in the status bar until it times out or is replaced.
+func (m *Model) flash(text string) tea.Cmd {
+	m.statusSeq++
+	m.statusMessage = text
+	seq := m.statusSeq
+	return tea.Tick(flashTimeout, func(time.Time) tea.Msg {
+		return clearStatusMsg(seq)
+	})
+}
+
+// layout sizes the viewport to the space left by the header, tabs, checks
+// panel and status bar.
+func (m *Model) layout() {
+	if m.width == 0 || m.height == 0 {
+		return
+	}
+	headerHeight := 1
+	tabHeight := 0
+	if len(m.tabs) > 0 {
+		tabHeight = 1
+	}
+	statusHeight := 1
+	vpHeight := m.height - headerHeight - tabHeight - statusHeight - m.checksPanelHeight()
+	if vpHeight < 1 {
+		vpHeight = 1
+	}
+
+	if !m.ready {
+		m.viewport = viewport.New(m.width, vpHeight)
+		m.viewport.SetContent(m.renderEntries())
+		m.ready = true
+	} else {
+		m.viewport.Width = m.width
+		m.viewport.Height = vpHeight
+	}
+}
+
 func (m *Model) renderTabs() string {
 	var tabs []string
+	m.tabEnds = m.tabEnds[:0]
+	end := 0
 	for i, tab := range m.tabs {
 		count := 0
 		if i == 0 {
@@ -388,13 +685,17 @@ func (m *Model) renderTabs() string {
 			}
 		}
 		label += " "
+		var rendered string
 		if i == m.activeTab {
-			tabs = append(tabs, ActiveTabStyle.Render(label))
+			rendered = ActiveTabStyle.Render(label)
 		} else if count > 0 {
-			tabs = append(tabs, TabWithChangesStyle.Render(label))
+			rendered = TabWithChangesStyle.Render(label)
 		} else {
-			tabs = append(tabs, InactiveTabStyle.Render(label))
+			rendered = InactiveTabStyle.Render(label)
 		}
+		end += lipgloss.Width(rendered)
+		m.tabEnds = append(m.tabEnds, end)
+		tabs = append(tabs, rendered)
 	}
 	row := lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
 	gap := m.width - lipgloss.Width(row)
@@ -414,6 +715,12 @@ func (m *Model) filteredEntries() []types.DiffEntry {
 				continue
 			}
 		}
+		if m.unreviewedOnly && m.isReviewed(e) {
+			continue
+		}
+		if !m.matchesFileFilter(e) {
+			continue
+		}
 		// We always show file entries now, diff visibility is handled separately
 		filtered = append(filtered, e)
 	}
@@ -421,8 +728,28 @@ func (m *Model) filteredEntries() []types.DiffEntry {
 }
 
 func (m *Model) renderEntries() string {
+	switch m.viewMode {
+	case viewTree:
+		return m.renderTree()
+	case viewStats:
+		return m.renderStats()
+	}
+	return m.renderList()
+}
+
+// renderList renders the time-ordered list of entries with their diffs,
+// recording where each entry and search match starts.
+func (m *Model) renderList() string {
 	entries := m.filteredEntries()
+	m.entryOffsets = m.entryOffsets[:0]
+	m.searchHits = m.searchHits[:0]
 	if len(entries) == 0 {
+		if m.fileFilter != "" && len(m.entries) > 0 {
+			return ContextLineStyle.Render(fmt.Sprintf("\n  No files match %q", m.fileFilter))
+		}
+		if m.unreviewedOnly && len(m.entries) > 0 {
+			return ContextLineStyle.Render("\n  All changes reviewed")
+		}
 		if m.activeTab == 0 {
 			return ContextLineStyle.Render("\n  Waiting for file changes...")
 		}
@@ -430,12 +757,21 @@ func (m *Model) renderEntries() string {
 	}
 
 	var b strings.Builder
+	lines := 0
 	for i, entry := range entries {
 		if i > 0 {
 			sep := SeparatorStyle.Render(strings.Repeat("─", m.width))
 			b.WriteString(sep + "\n")
+			lines++
 		}
-		b.WriteString(renderEntry(entry, m.width, m))
+		m.entryOffsets = append(m.entryOffsets, lines)
+		m.pendingHits = m.pendingHits[:0]
+		rendered := renderEntry(entry, m.width, m)
+		b.WriteString(rendered)
+		for _, hit := range m.pendingHits {
+			m.searchHits = append(m.searchHits, lines+hit)
+		}
+		lines += strings.Count(rendered, "\n")
 	}
 	return b.String()
 }
@@ -445,6 +781,9 @@ func renderEntry(e types.DiffEntry, width int, m *Model) string {
 
 	ts := TimestampStyle.Render(e.Timestamp.Format("15:04:05"))
 	fp := FilePathStyle.Render(e.FilePath)
+	if e.Commit != nil {
+		fp = CommitStyle.Render(commitTitle(e.Commit))
+	}
 
 	hiddenIndicator := ""
 	if m != nil && !m.isFileVisible(e.FilePath) {
@@ -452,15 +791,35 @@ func renderEntry(e types.DiffEntry, width int, m *Model) string {
 	}
 
 	activeIndicator := ""
-	if m != nil && m.selectedFilePath == e.FilePath {
-		activeIndicator = ActiveFileStyle.Render(" ▲ ACTIVE ")
+	if m != nil {
+		activeIndicator = m.diffToggle(e.FilePath)
+		if m.selectedFilePath == e.FilePath {
+			activeIndicator += ActiveFileStyle.Render(" ▲ ACTIVE ")
+		}
+	}
+
+	badges := ""
+	if m != nil {
+		if isSubmoduleDiff(e.Diff) {
+			badges += TreeDirStyle.Render(" [submodule]")
+		}
+		if m.isReviewed(e) {
+			badges += ReviewedStyle.Render(" ✓ reviewed")
+		}
+		badges += m.renderHookBadges(e.FilePath)
+		if n := m.commentCount(m.relPath(e.FilePath), e.Repo); n > 0 {
+			badges += CommentBadgeStyle.Render(fmt.Sprintf("  ✎ %d", n))
+		}
 	}
 
 	if e.Repo != "" {
 		repo := RepoTagStyle.Render(e.Repo)
-		b.WriteString(activeIndicator + repo + " " + fp + "  " + ts + hiddenIndicator + "\n")
+		b.WriteString(activeIndicator + repo + " " + fp + "  " + ts + hiddenIndicator + badges + "\n")
 	} else {
-		b.WriteString(activeIndicator + fp + "  " + ts + hiddenIndicator + "\n")
+		b.WriteString(activeIndicator + fp + "  " + ts + hiddenIndicator + badges + "\n")
+	}
+	if e.Commit != nil {
+		b.WriteString(renderCommitInfo(e.Commit))
 	}
 
 	if e.Error != "" {
@@ -474,37 +833,90 @@ func renderEntry(e types.DiffEntry, width int, m *Model) string {
 	}
 
 	if m != nil && !m.isDiffVisible(e.FilePath) {
-		b.WriteString(HiddenFileStyle.Render("  [DIFF HIDDEN - press t to show]") + "\n")
+		b.WriteString(HiddenFileStyle.Render(fmt.Sprintf("  [DIFF HIDDEN - press %s to show]", keyHint(m.keys.ToggleDiff))) + "\n")
 		return b.String()
 	}
 
-	lines := strings.Split(e.Diff, "\n")
+	diff := e.Diff
+	if m != nil && m.hasSinceLook(e) {
+		diff = m.displayDiff(e)
+		if diff == "" {
+			b.WriteString(SinceLookStyle.Render(fmt.Sprintf("  (no changes since last look - press %s for full diff)", keyHint(m.keys.SinceLook))) + "\n")
+			return b.String()
+		}
+		b.WriteString(SinceLookStyle.Render(fmt.Sprintf("  (changes since last look - press %s for full diff)", keyHint(m.keys.SinceLook))) + "\n")
+	}
+
+	currentHunk := -1
+	if m != nil && m.selectedFilePath == e.FilePath {
+		currentHunk = m.currentHunkIndex()
+	}
+	expanded := m != nil && m.expandedEntries[e.FilePath]
+	collapsed := false
+
+	hunks := differ.ParseHunks(diff)
+	lines := strings.Split(diff, "\n")
+	emphasis := wordDiffSpans(lines)
+	header := differ.HeaderLines(lines)
 	rendered := 0
-	for _, line := range lines {
-		if strings.HasPrefix(line, "diff --git") ||
+	hunkIndex := -1
+	for i, line := range lines {
+		if e.Commit != nil && strings.HasPrefix(line, "diff --git") {
+			// A commit spans several files; name each one
+			collapsed = false
+			b.WriteString(TreeFileStyle.Render("  "+commitDiffPath(line)) + "\n")
+			rendered++
+			continue
+		}
+		if header[i] && (strings.HasPrefix(line, "diff --git") ||
 			strings.HasPrefix(line, "index ") ||
 			strings.HasPrefix(line, "--- ") ||
 			strings.HasPrefix(line, "+++ ") ||
 			strings.HasPrefix(line, "new file") ||
 			strings.HasPrefix(line, "old mode") ||
-			strings.HasPrefix(line, "new mode") {
+			strings.HasPrefix(line, "new mode")) {
 			continue
 		}
 
-		if rendered >= maxDiffLines {
-			b.WriteString(ErrorStyle.Render("  ... (truncated)") + "\n")
+		if collapsed && !strings.HasPrefix(line, "@@") {
+			continue
+		}
+
+		if rendered >= maxDiffLines && !expanded {
+			hint := "x"
+			if m != nil {
+				hint = keyHint(m.keys.Expand)
+			}
+			b.WriteString(ErrorStyle.Render(fmt.Sprintf("  ... (truncated - press %s to expand)", hint)) + "\n")
 			break
 		}
 
 		switch {
 		case strings.HasPrefix(line, "@@"):
-			b.WriteString(HunkHeaderStyle.Render(line) + "\n")
+			hunkIndex++
+			mark := ""
+			collapsed = false
+			if m != nil && hunkIndex < len(hunks) {
+				full, ok := m.fullHunk(e, hunks[hunkIndex])
+				if ok && m.isHunkReviewed(e.FilePath, full) {
+					mark = ReviewedStyle.Render(" ✓")
+				}
+				if ok && m.isHunkCollapsed(e.FilePath, full) {
+					collapsed = true
+					mark += HiddenFileStyle.Render(fmt.Sprintf(" (%d lines collapsed - press %s to expand)", len(hunks[hunkIndex].Lines), keyHint(m.keys.CollapseHunk)))
+				}
+			}
+			if hunkIndex == currentHunk {
+				b.WriteString(SelectedHunkStyle.Render("▶ "+line) + mark + "\n")
+			} else {
+				b.WriteString(HunkHeaderStyle.Render(line) + mark + "\n")
+			}
 		case strings.HasPrefix(line, "+"):
-			b.WriteString(AddedLineStyle.Render(line) + "\n")
+			b.WriteString(m.renderBodyLine(line, AddedLineStyle, AddedWordStyle, emphasis[i], &b) + "\n")
 		case strings.HasPrefix(line, "-"):
-			b.WriteString(RemovedLineStyle.Render(line) + "\n")
+			b.WriteString(m.renderBodyLine(line, RemovedLineStyle, RemovedWordStyle, emphasis[i], &b) + "\n")
 		default:
-			b.WriteString(ContextLineStyle.Render(line) + "\n")
+			b.WriteString(m.renderBodyLine(line, ContextLineStyle, ContextLineStyle, nil, &b) + "\n")
 		}
 		rendered++
 	}
@@ -551,11 +963,21 @@ func (m *Model) toggleFileVisibility(filePath string) tea.Cmd {
 	}
 }
 
-// untoggleFileVisibility shows a hidden file and returns a command to update the view
-func (m *Model) untoggleFileVisibility(filePath string) tea.Cmd {
-	return func() tea.Msg {
-		return UntoggleFileMsg(filePath)
+// setAllDiffsVisible shows or hides the diffs of all entries.
+func (m *Model) setAllDiffsVisible(visible bool) {
+	m.visibleFilesMu.Lock()
+	for _, e := range m.entries {
+		m.visibleFiles[e.FilePath] = visible
+	}
+	m.showHiddenCount = 0
+	for path := range m.visibleFiles {
+		m.visibleFiles[path] = visible
+		if !visible {
+			m.showHiddenCount++
+		}
 	}
+	m.visibleFilesMu.Unlock()
+	m.viewport.SetContent(m.renderEntries())
 }
 
 // navigateFiles moves the selection up or down by the specified delta
@@ -567,8 +989,7 @@ func (m *Model) navigateFiles(delta int) (*Model, tea.Cmd) {
 
 	// Initialize selection if not properly set (index is 0 but path is empty)
 	if m.selectedFilePath == "" {
-		m.selectedFileIndex = 0
-		m.selectedFilePath = filtered[0].FilePath
+		m.selectEntry(0, filtered[0].FilePath)
 		m.ensureSelectedFileVisible()
 		return m, nil
 	}
@@ -589,8 +1010,7 @@ func (m *Model) navigateFiles(delta int) (*Model, tea.Cmd) {
 		}
 	}
 
-	m.selectedFileIndex = newIndex
-	m.selectedFilePath = filtered[newIndex].FilePath
+	m.selectEntry(newIndex, filtered[newIndex].FilePath)
 
 	// Ensure the selected file is visible in the viewport
 	m.ensureSelectedFileVisible()
@@ -598,20 +1018,76 @@ func (m *Model) navigateFiles(delta int) (*Model, tea.Cmd) {
 	return m, nil
 }
 
+// selectEntry moves the selection. The file being left counts as looked at,
+// so its later changes are shown relative to its content now.
+func (m *Model) selectEntry(index int, filePath string) {
+	if filePath != m.selectedFilePath {
+		m.markSeen()
+	}
+	m.selectedFileIndex = index
+	m.selectedFilePath = filePath
+}
+
+// syncSelection re-renders the entries and keeps the selection on a listed
+// entry, moving it to the nearest one when the selected entry was filtered out.
+func (m *Model) syncSelection() {
+	filtered := m.filteredEntries()
+	if m.selectedFilePath != "" {
+		found := false
+		for i, e := range filtered {
+			if e.FilePath == m.selectedFilePath {
+				m.selectedFileIndex = i
+				found = true
+				break
+			}
+		}
+		if !found {
+			if len(filtered) == 0 {
+				m.selectEntry(0, "")
+			} else {
+				idx := min(m.selectedFileIndex, len(filtered)-1)
+				m.selectEntry(idx, filtered[idx].FilePath)
+			}
+		}
+	}
+	m.viewport.SetContent(m.renderEntries())
+}
+
+// selectFile selects the entry for filePath, switching to the "All" tab if the
+// active tab does not contain it. It reports whether the entry was found.
+func (m *Model) selectFile(filePath string) bool {
+	prevTab := m.activeTab
+	for attempt := 0; attempt < 2; attempt++ {
+		for i, e := range m.filteredEntries() {
+			if e.FilePath == filePath {
+				m.selectEntry(i, filePath)
+				m.ensureSelectedFileVisible()
+				return true
+			}
+		}
+		if m.activeTab == 0 {
+			break
+		}
+		m.activeTab = 0
+	}
+	m.activeTab = prevTab
+	return false
+}
+
 // ensureSelectedFileVisible scrolls the viewport to make sure the selected file is visible
 func (m *Model) ensureSelectedFileVisible() {
 	m.viewport.SetContent(m.renderEntries())
-
-	if m.selectedFileIndex < 5 {
-		m.viewport.GotoTop()
+	if m.viewMode != viewList {
 		return
 	}
 
-	filtered := m.filteredEntries()
-	if m.selectedFileIndex >= len(filtered)-5 {
-		m.viewport.GotoBottom()
+	if m.selectedFileIndex >= len(m.entryOffsets) {
 		return
 	}
+	offset := m.entryOffsets[m.selectedFileIndex]
+	if offset < m.viewport.YOffset || offset >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(offset)
+	}
 }
 
 func loadInitialEntries(d differ.Differ) tea.Cmd {
@@ -653,52 +1129,47 @@ func updateBranches(d differ.Differ) tea.Cmd {
 	}
 }
 
-func waitForChange(ch <-chan string, d differ.Differ) tea.Cmd {
+// waitForWatcherError waits for the next error reported by the source.
+func waitForWatcherError(src watcher.Source) tea.Cmd {
 	return func() tea.Msg {
-		path, ok := <-ch
+		err, ok := <-src.Errors()
 		if !ok {
 			return nil
 		}
+		return WatcherErrorMsg{Err: err}
+	}
+}
 
-		logMessage(fmt.Sprintf("MODEL: Received change from channel: %s", path))
+// waitForChange waits for the next event from the source and diffs the
+// changed file.
+func waitForChange(src watcher.Source, d differ.Differ) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-src.Events()
+		if !ok {
+			return nil
+		}
 
-		entry, err := d.Diff(path)
+		logMessage(fmt.Sprintf("MODEL: Received %s event from channel: %s", ev.Op, ev.Path))
+		switch ev.Op {
+		case watcher.OpGit:
+			return GitOperationMsg(ev)
+		case watcher.OpRepoAdd, watcher.OpRepoRemove:
+			return changeRepos(d, ev)
+		}
+
+		entry, err := d.Diff(ev.Path)
 		if err != nil {
-			logMessage(fmt.Sprintf("MODEL: Error getting diff for %s: %v", path, err))
+			logMessage(fmt.Sprintf("MODEL: Error getting diff for %s: %v", ev.Path, err))
 			entry = types.DiffEntry{
-				FilePath:  path,
-				Timestamp: time.Now(),
+				FilePath:  ev.Path,
+				Timestamp: ev.Time,
+				Repo:      ev.Repo,
 				Error:     err.Error(),
 			}
 		} else {
-			logMessage(fmt.Sprintf("MODEL: Successfully got diff for %s", path))
+			logMessage(fmt.Sprintf("MODEL: Successfully got diff for %s", ev.Path))
+			entry.Timestamp = ev.Time
 		}
 		return FileChangedMsg(entry)
 	}
 }
-
-// processPendingChanges processes all pending changes from the channel
-func processPendingChanges(ch <-chan string, d differ.Differ) tea.Cmd {
-	return func() tea.Msg {
-		// First, try to receive one change immediately
-		select {
-		case path, ok := <-ch:
-			if !ok {
-				return nil
-			}
-			entry, err := d.Diff(path)
-			if err != nil {
-				entry = types.DiffEntry{
-					FilePath:  path,
-					Timestamp: time.Now(),
-					Error:     err.Error(),
-				}
-			}
-			return FileChangedMsg(entry)
-		default:
-			// No changes available immediately, return nil
-			// This will cause the model to re-schedule this command
-			return nil
-		}
-	}
-}
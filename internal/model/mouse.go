@@ -0,0 +1,141 @@
+// mouse.go handles mouse clicks and wheel scrolling.
+package model
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+)
+
+const (
+	// toggleWidth is the width of the hide/show glyph at the start of entry headers.
+	toggleWidth = 2
+	// headerLines is the height of an entry header; the file path has a top margin.
+	headerLines = 2
+)
+
+// entryHeaderLines returns the height of an entry's header. A commit has an
+// extra line with its author and files.
+func entryHeaderLines(e types.DiffEntry) int {
+	if e.Commit != nil {
+		return headerLines + 1
+	}
+	return headerLines
+}
+
+// diffToggle returns the glyph that hides or shows an entry's diff when clicked.
+func (m *Model) diffToggle(filePath string) string {
+	if m.isDiffVisible(filePath) {
+		return TimestampStyle.Render("▾ ")
+	}
+	return HiddenFileStyle.Render("▸ ")
+}
+
+// viewportTop returns the screen row of the first viewport line.
+func (m *Model) viewportTop() int {
+	if len(m.tabs) > 0 {
+		return 2
+	}
+	return 1
+}
+
+// updateMouse handles clicks on tabs, entry headers and tree rows, and wheel
+// scrolling over the entry pane.
+func (m *Model) updateMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.showHelp || m.showFeedback || m.showHooks || m.promptKind != promptNone {
+		return nil
+	}
+	if msg.Action != tea.MouseActionPress {
+		return nil
+	}
+
+	top := m.viewportTop()
+	inViewport := msg.Y >= top && msg.Y < top+m.viewport.Height
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		if inViewport {
+			m.viewport.ScrollUp(m.viewport.MouseWheelDelta)
+		}
+		return nil
+	case tea.MouseButtonWheelDown:
+		if inViewport {
+			m.viewport.ScrollDown(m.viewport.MouseWheelDelta)
+		}
+		return nil
+	case tea.MouseButtonLeft:
+	default:
+		return nil
+	}
+
+	if len(m.tabs) > 0 && msg.Y == 1 {
+		m.clickTab(msg.X)
+		return nil
+	}
+	if !inViewport {
+		return nil
+	}
+
+	row := m.viewport.YOffset + msg.Y - top
+	switch m.viewMode {
+	case viewTree:
+		m.clickTreeRow(row)
+		return nil
+	case viewList:
+		return m.clickEntry(row, msg.X)
+	}
+	return nil
+}
+
+// clickTab activates the tab under column x.
+func (m *Model) clickTab(x int) {
+	for i, end := range m.tabEnds {
+		if x < end {
+			if i != m.activeTab {
+				m.activeTab = i
+				m.syncSelection()
+				m.viewport.GotoTop()
+			}
+			return
+		}
+	}
+}
+
+// clickTreeRow moves the tree cursor to row, opening the node when it was
+// already under the cursor.
+func (m *Model) clickTreeRow(row int) {
+	nodes := m.visibleTreeNodes()
+	if row >= len(nodes) {
+		return
+	}
+	if row == m.treeCursor {
+		m.openTreeNode(nodes[row])
+		return
+	}
+	m.treeCursor = row
+	m.viewport.SetContent(m.renderEntries())
+}
+
+// clickEntry selects the entry whose header is on row. Clicking the toggle
+// glyph or the hidden-diff placeholder hides or shows the diff instead.
+func (m *Model) clickEntry(row, x int) tea.Cmd {
+	filtered := m.filteredEntries()
+	for i, offset := range m.entryOffsets {
+		if i >= len(filtered) {
+			break
+		}
+		e := filtered[i]
+		height := entryHeaderLines(e)
+		switch {
+		case row == offset && x < toggleWidth:
+			return m.toggleFileVisibility(e.FilePath)
+		case row >= offset && row < offset+height:
+			m.selectEntry(i, e.FilePath)
+			m.viewport.SetContent(m.renderEntries())
+			return nil
+		case row == offset+height && e.Error == "" && e.Diff != "" && !m.isDiffVisible(e.FilePath):
+			return m.toggleFileVisibility(e.FilePath)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// prompt.go provides the single-line input shown in place of the status bar
+// for actions that need text from the user.
+package model
+
+import (
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptKind identifies what the active prompt's input is used for.
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptComment
+	promptCommentLine
+	promptFilter
+	promptSearch
+	promptBase
+)
+
+// openPrompt shows the input with the given label and initial value.
+func (m *Model) openPrompt(kind promptKind, label, value string) tea.Cmd {
+	m.prompt = textinput.New()
+	m.prompt.Prompt = label
+	m.prompt.PromptStyle = PromptStyle
+	m.prompt.Width = m.width - len(label) - 2
+	m.prompt.Cursor.SetMode(cursor.CursorStatic)
+	m.prompt.SetValue(value)
+	m.promptKind = kind
+	return m.prompt.Focus()
+}
+
+func (m *Model) closePrompt() {
+	m.prompt.Blur()
+	m.promptKind = promptNone
+}
+
+// updatePrompt handles a key while the prompt is active. Enter submits the
+// input and Esc cancels it.
+func (m *Model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		kind := m.promptKind
+		m.closePrompt()
+		m.cancelPrompt(kind)
+		return m, nil
+	case tea.KeyEnter:
+		kind, value := m.promptKind, m.prompt.Value()
+		m.closePrompt()
+		return m, m.submitPrompt(kind, value)
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	}
+
+	var cmd tea.Cmd
+	m.prompt, cmd = m.prompt.Update(msg)
+	m.promptChanged(m.promptKind, m.prompt.Value())
+	return m, cmd
+}
+
+// promptChanged applies live-updating prompts as the user types.
+func (m *Model) promptChanged(kind promptKind, value string) {
+	switch kind {
+	case promptFilter:
+		m.setFileFilter(value)
+	}
+}
+
+// cancelPrompt undoes the effect of a live-updating prompt dismissed with Esc.
+func (m *Model) cancelPrompt(kind promptKind) {
+	switch kind {
+	case promptFilter:
+		m.setFileFilter("")
+	}
+}
+
+func (m *Model) submitPrompt(kind promptKind, value string) tea.Cmd {
+	switch kind {
+	case promptComment:
+		return m.addComment(value)
+	case promptCommentLine:
+		return m.commentOnLine(value)
+	case promptSearch:
+		return m.setContentSearch(value)
+	case promptBase:
+		return setBase(m.differ, value)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// repos.go keeps the repositories of multi-repo mode in step with the watched
+// directory: repositories cloned or initialized under it get a tab, and
+// deleted ones lose theirs along with their entries.
+package model
+
+import (
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/watcher"
+)
+
+// changeRepos applies a repository event to the differ and returns the
+// message telling the model about it.
+func changeRepos(d differ.Differ, ev watcher.Event) tea.Msg {
+	md, ok := d.(*differ.MultiDiffer)
+	if !ok {
+		return RepoChangedMsg{}
+	}
+	if ev.Op == watcher.OpRepoRemove {
+		return RepoChangedMsg{Name: md.RemoveRepo(ev.Path), Removed: true}
+	}
+	if err := md.AddRepo(ev.Path, ev.Repo); err != nil {
+		logMessage(fmt.Sprintf("MODEL: Cannot add repository %s: %v", ev.Path, err))
+		return RepoChangedMsg{}
+	}
+	return RepoChangedMsg{Name: ev.Repo, Branch: differ.GetBranch(ev.Path)}
+}
+
+// applyRepoChange updates the tabs, branches and entries after a repository
+// was added or removed.
+func (m *Model) applyRepoChange(msg RepoChangedMsg) tea.Cmd {
+	if msg.Name == "" {
+		return nil
+	}
+	if m.branches == nil {
+		m.branches = make(map[string]string)
+	}
+	if msg.Removed {
+		delete(m.branches, msg.Name)
+		kept := make([]types.DiffEntry, 0, len(m.entries))
+		for _, e := range m.entries {
+			if e.Repo != msg.Name {
+				kept = append(kept, e)
+			}
+		}
+		m.entries = kept
+	} else {
+		m.branches[msg.Name] = msg.Branch
+	}
+	m.updateRepoTabs()
+	m.syncSelection()
+
+	if msg.Removed {
+		return m.flash("Repository removed: " + msg.Name)
+	}
+	return tea.Batch(
+		m.flash("New repository: "+msg.Name),
+		loadRepoEntries(m.differ, msg.Name),
+	)
+}
+
+// updateRepoTabs rebuilds the tabs from the differ's repositories, keeping the
+// active tab on the same repository when it still exists.
+func (m *Model) updateRepoTabs() {
+	active := ""
+	if m.activeTab > 0 && m.activeTab < len(m.tabs) {
+		active = m.tabs[m.activeTab]
+	}
+	var names []string
+	for _, name := range m.differ.RepoRootsWithNames() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	m.tabs = nil
+	m.activeTab = 0
+	if len(names) > 1 {
+		m.tabs = append([]string{"All"}, names...)
+	}
+	for i, tab := range m.tabs {
+		if i > 0 && tab == active {
+			m.activeTab = i
+		}
+	}
+	m.layout()
+}
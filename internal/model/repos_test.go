@@ -0,0 +1,57 @@
+package model
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/watcher"
+)
+
+func TestRepoChanges(t *testing.T) {
+	parent := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		initRepo(t, filepath.Join(parent, name))
+	}
+	md, err := differ.NewMulti(map[string]string{filepath.Join(parent, "a"): "a", filepath.Join(parent, "b"): "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(md)
+	m.updateRepoTabs()
+	m.activeTab = 2 // b
+
+	// A repository cloned while running, with a change in it
+	rootC := filepath.Join(parent, "c")
+	initRepo(t, rootC)
+	writeTestFile(t, filepath.Join(rootC, "README"), "changed\n")
+	m.Update(changeRepos(md, watcher.Event{Op: watcher.OpRepoAdd, Path: rootC, Repo: "c"}))
+	m.Update(loadRepoEntries(md, "c")())
+
+	if want := []string{"All", "a", "b", "c"}; !reflect.DeepEqual(m.tabs, want) {
+		t.Errorf("tabs after adding c = %q, want %q", m.tabs, want)
+	}
+	if _, ok := m.branches["c"]; !ok {
+		t.Error("no branch recorded for c")
+	}
+	if !hasEntry(m.entries, filepath.Join(rootC, "README")) {
+		t.Errorf("entries %q lack c's changed file", entryNames(parent, m.entries))
+	}
+
+	m.Update(changeRepos(md, watcher.Event{Op: watcher.OpRepoRemove, Path: rootC}))
+	if want := []string{"All", "a", "b"}; !reflect.DeepEqual(m.tabs, want) {
+		t.Errorf("tabs after removing c = %q, want %q", m.tabs, want)
+	}
+	if m.tabs[m.activeTab] != "b" {
+		t.Errorf("active tab = %q, want it to stay on b", m.tabs[m.activeTab])
+	}
+	if len(m.entries) != 0 {
+		t.Errorf("entries %q remain from the removed repository", entryNames(parent, m.entries))
+	}
+
+	m.Update(changeRepos(md, watcher.Event{Op: watcher.OpRepoRemove, Path: filepath.Join(parent, "b")}))
+	if m.tabs != nil || m.activeTab != 0 {
+		t.Errorf("tabs = %q, active %d with one repository left, want none", m.tabs, m.activeTab)
+	}
+}
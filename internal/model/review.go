@@ -0,0 +1,158 @@
+// review.go tracks which files and hunks have been reviewed. Marks are tied to
+// a hash of the reviewed content, so they clear themselves as soon as the
+// agent changes that file or hunk again.
+package model
+
+import (
+	"hash/fnv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+)
+
+func contentHash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// changedLines returns only the added and removed lines of a hunk, so the
+// hashes below do not depend on line numbers or on how much context is shown.
+func changedLines(lines []string) string {
+	var b strings.Builder
+	for _, l := range lines {
+		if strings.HasPrefix(l, "+") || strings.HasPrefix(l, "-") {
+			b.WriteString(l + "\n")
+		}
+	}
+	return b.String()
+}
+
+// diffHash identifies the content of a whole diff. Only hunk bodies count, so
+// file headers stay out while removed lines that look like them stay in.
+func diffHash(diff string) uint64 {
+	var b strings.Builder
+	for _, h := range differ.ParseHunks(diff) {
+		b.WriteString(changedLines(h.Lines))
+	}
+	return contentHash(b.String())
+}
+
+// hunkHash identifies the content of a hunk. A hunk stays the same when edits
+// elsewhere in the file shift its line numbers.
+func hunkHash(h differ.Hunk) uint64 {
+	return contentHash(changedLines(h.Lines))
+}
+
+// isReviewed reports whether the whole entry is reviewed: either marked as a
+// file, or every one of its hunks marked individually.
+func (m *Model) isReviewed(e types.DiffEntry) bool {
+	if hash, ok := m.reviewedFiles[e.FilePath]; ok && hash == diffHash(e.Diff) {
+		return true
+	}
+	hunks := differ.ParseHunks(e.Diff)
+	if len(hunks) == 0 {
+		return false
+	}
+	for _, h := range hunks {
+		if !m.isHunkReviewed(e.FilePath, h) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Model) isHunkReviewed(filePath string, h differ.Hunk) bool {
+	return m.reviewedHunks[filePath][hunkHash(h)]
+}
+
+// toggleFileReviewed marks or unmarks the selected entry as reviewed.
+func (m *Model) toggleFileReviewed() tea.Cmd {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return m.flash("No file selected")
+	}
+	if m.isReviewed(e) {
+		delete(m.reviewedFiles, e.FilePath)
+		delete(m.reviewedHunks, e.FilePath)
+	} else {
+		m.reviewedFiles[e.FilePath] = diffHash(e.Diff)
+	}
+	m.syncSelection()
+	return nil
+}
+
+// toggleHunkReviewed marks or unmarks the current hunk as reviewed.
+func (m *Model) toggleHunkReviewed() tea.Cmd {
+	e, ok := m.selectedEntry()
+	if !ok {
+		return m.flash("No file selected")
+	}
+	h, ok := m.currentFullHunk()
+	if !ok {
+		return m.flash("No hunk to mark")
+	}
+
+	// Unmarking a hunk of a file reviewed as a whole converts the file mark
+	// into marks on its other hunks
+	if hash, ok := m.reviewedFiles[e.FilePath]; ok && hash == diffHash(e.Diff) {
+		delete(m.reviewedFiles, e.FilePath)
+		for _, other := range differ.ParseHunks(e.Diff) {
+			m.markHunk(e.FilePath, hunkHash(other), true)
+		}
+	}
+
+	hash := hunkHash(h)
+	reviewed := !m.reviewedHunks[e.FilePath][hash]
+	m.markHunk(e.FilePath, hash, reviewed)
+	if reviewed && !m.isReviewed(e) {
+		m.navigateHunks(1)
+	}
+	m.syncSelection()
+	return nil
+}
+
+func (m *Model) markHunk(filePath string, hash uint64, reviewed bool) {
+	if !reviewed {
+		delete(m.reviewedHunks[filePath], hash)
+		return
+	}
+	if m.reviewedHunks[filePath] == nil {
+		m.reviewedHunks[filePath] = make(map[uint64]bool)
+	}
+	m.reviewedHunks[filePath][hash] = true
+}
+
+// pruneReviewed drops review marks that no longer match the entry's content.
+func (m *Model) pruneReviewed(e types.DiffEntry) {
+	if hash, ok := m.reviewedFiles[e.FilePath]; ok && hash != diffHash(e.Diff) {
+		delete(m.reviewedFiles, e.FilePath)
+	}
+	marks := m.reviewedHunks[e.FilePath]
+	if len(marks) == 0 {
+		return
+	}
+	current := make(map[uint64]bool)
+	for _, h := range differ.ParseHunks(e.Diff) {
+		current[hunkHash(h)] = true
+	}
+	for hash := range marks {
+		if !current[hash] {
+			delete(marks, hash)
+		}
+	}
+}
+
+// reviewedCount returns how many of the entries are fully reviewed.
+func (m *Model) reviewedCount(entries []types.DiffEntry) int {
+	count := 0
+	for _, e := range entries {
+		if m.isReviewed(e) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,201 @@
+// search.go implements the "/" prompt that fuzzy-filters the entry list by
+// repo name and path, and the "s" prompt that searches the text of all
+// visible diffs with a regular expression.
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+)
+
+// fuzzyMatch reports whether all characters of pattern appear in text in
+// order, ignoring case. Spaces in the pattern separate terms that must each match.
+func fuzzyMatch(pattern, text string) bool {
+	text = strings.ToLower(text)
+	for _, term := range strings.Fields(strings.ToLower(pattern)) {
+		if !subsequence(term, text) {
+			return false
+		}
+	}
+	return true
+}
+
+func subsequence(pattern, text string) bool {
+	runes := []rune(pattern)
+	i := 0
+	for _, r := range text {
+		if i == len(runes) {
+			break
+		}
+		if runes[i] == r {
+			i++
+		}
+	}
+	return i == len(runes)
+}
+
+// matchesFileFilter reports whether an entry passes the "/" filter.
+func (m *Model) matchesFileFilter(e types.DiffEntry) bool {
+	if m.fileFilter == "" {
+		return true
+	}
+	return fuzzyMatch(m.fileFilter, e.Repo+"/"+m.relPath(e.FilePath))
+}
+
+// setFileFilter applies a new filter and keeps the selection on a match.
+func (m *Model) setFileFilter(filter string) {
+	m.fileFilter = strings.TrimSpace(filter)
+	m.syncSelection()
+	if m.selectedFilePath == "" {
+		if filtered := m.filteredEntries(); len(filtered) > 0 {
+			m.selectEntry(0, filtered[0].FilePath)
+		}
+	}
+	m.ensureSelectedFileVisible()
+}
+
+// searchScope limits a content search to some kinds of diff lines.
+type searchScope int
+
+const (
+	scopeAll searchScope = iota
+	scopeAdded
+	scopeRemoved
+)
+
+// setContentSearch compiles a diff search. A leading "+" or "-" limits the
+// search to added or removed lines. An empty query clears the search.
+func (m *Model) setContentSearch(query string) tea.Cmd {
+	m.searchQuery = ""
+	m.searchRe = nil
+	m.searchScope = scopeAll
+	m.searchIndex = -1
+
+	pattern := query
+	scope := scopeAll
+	switch {
+	case strings.HasPrefix(pattern, "+"):
+		scope, pattern = scopeAdded, pattern[1:]
+	case strings.HasPrefix(pattern, "-"):
+		scope, pattern = scopeRemoved, pattern[1:]
+	}
+	if pattern == "" {
+		m.viewport.SetContent(m.renderEntries())
+		return nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		m.viewport.SetContent(m.renderEntries())
+		return m.flash("Invalid regex: " + err.Error())
+	}
+	m.searchQuery = query
+	m.searchRe = re
+	m.searchScope = scope
+	m.viewport.SetContent(m.renderEntries())
+
+	if len(m.searchHits) == 0 {
+		return m.flash(fmt.Sprintf("No matches for %s", query))
+	}
+	m.jumpToSearchHit(1)
+	return nil
+}
+
+// renderBodyLine renders a diff body line, emphasizing the word-level
+// changes in emph and content search matches, and recording search hits.
+func (m *Model) renderBodyLine(line string, style, emphStyle lipgloss.Style, emph []span, b *strings.Builder) string {
+	search := m.searchSpans(line)
+	if len(search) > 0 {
+		m.pendingHits = append(m.pendingHits, strings.Count(b.String(), "\n"))
+	}
+	if len(search) == 0 && len(emph) == 0 {
+		return style.Render(line)
+	}
+
+	// Paint every byte with its style; search matches take precedence
+	const (
+		paintBase = iota
+		paintEmph
+		paintSearch
+	)
+	paint := make([]int, len(line))
+	for _, sp := range emph {
+		for i := sp.start; i < sp.end && i < len(line); i++ {
+			paint[i] = paintEmph
+		}
+	}
+	for _, sp := range search {
+		for i := sp.start; i < sp.end && i < len(line); i++ {
+			paint[i] = paintSearch
+		}
+	}
+
+	styles := []lipgloss.Style{style, emphStyle, SearchMatchStyle}
+	var out strings.Builder
+	start := 0
+	for i := 1; i <= len(line); i++ {
+		if i == len(line) || paint[i] != paint[start] {
+			out.WriteString(styles[paint[start]].Render(line[start:i]))
+			start = i
+		}
+	}
+	return out.String()
+}
+
+// searchSpans returns the ranges of a diff line matched by the content search.
+// Only the content is matched, not the leading +/- marker.
+func (m *Model) searchSpans(line string) []span {
+	if m == nil || m.searchRe == nil || len(line) == 0 {
+		return nil
+	}
+	switch m.searchScope {
+	case scopeAdded:
+		if line[0] != '+' {
+			return nil
+		}
+	case scopeRemoved:
+		if line[0] != '-' {
+			return nil
+		}
+	}
+
+	var spans []span
+	for _, loc := range m.searchRe.FindAllStringIndex(line[1:], -1) {
+		if loc[0] != loc[1] {
+			spans = append(spans, span{loc[0] + 1, loc[1] + 1})
+		}
+	}
+	return spans
+}
+
+// jumpToSearchHit scrolls to the next (delta 1) or previous (delta -1)
+// content search match and selects the entry it belongs to.
+func (m *Model) jumpToSearchHit(delta int) {
+	if len(m.searchHits) == 0 {
+		return
+	}
+	if m.searchIndex < 0 && delta < 0 {
+		m.searchIndex = 0
+	}
+	m.searchIndex = (m.searchIndex + delta + len(m.searchHits)) % len(m.searchHits)
+	hit := m.searchHits[m.searchIndex]
+
+	// The hit belongs to the last entry starting at or before it
+	filtered := m.filteredEntries()
+	for i := len(m.entryOffsets) - 1; i >= 0; i-- {
+		if m.entryOffsets[i] <= hit && i < len(filtered) {
+			if filtered[i].FilePath != m.selectedFilePath {
+				m.selectEntry(i, filtered[i].FilePath)
+				m.viewport.SetContent(m.renderEntries())
+			}
+			break
+		}
+	}
+	m.viewport.SetYOffset(max(hit-m.viewport.Height/3, 0))
+}
@@ -0,0 +1,188 @@
+// stats.go renders the session statistics view: how many files changed, lines
+// added and removed per file and repo, churn over time and the most rewritten
+// files, to give a sense of how big and how chaotic an agent run was.
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/watcher"
+)
+
+// statsTopFiles is the number of files listed in each ranking.
+const statsTopFiles = 10
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sessionStats accumulates change events observed during the session.
+type sessionStats struct {
+	started  time.Time
+	events   []time.Time
+	rewrites map[string]int
+}
+
+func newSessionStats() sessionStats {
+	return sessionStats{
+		started:  time.Now(),
+		rewrites: make(map[string]int),
+	}
+}
+
+// record counts a change event for a file.
+func (s *sessionStats) record(e types.DiffEntry) {
+	s.events = append(s.events, e.Timestamp)
+	s.rewrites[e.FilePath]++
+}
+
+// churn returns the number of change events in each minute of the session,
+// limited to the most recent buckets.
+func (s *sessionStats) churn(buckets int) []int {
+	minutes := int(time.Since(s.started).Minutes()) + 1
+	counts := make([]int, minutes)
+	for _, t := range s.events {
+		idx := int(t.Sub(s.started).Minutes())
+		if idx >= 0 && idx < minutes {
+			counts[idx]++
+		}
+	}
+	if len(counts) > buckets {
+		counts = counts[len(counts)-buckets:]
+	}
+	return counts
+}
+
+func sparkline(counts []int) string {
+	peak := 0
+	for _, c := range counts {
+		peak = max(peak, c)
+	}
+	var b strings.Builder
+	for _, c := range counts {
+		if c == 0 || peak == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(sparkBlocks[(c*(len(sparkBlocks)-1)+peak-1)/peak])
+	}
+	return b.String()
+}
+
+type fileStats struct {
+	path     string
+	repo     string
+	added    int
+	removed  int
+	rewrites int
+}
+
+func (m *Model) renderStats() string {
+	s := &m.stats
+	var files []fileStats
+	totalAdded, totalRemoved := 0, 0
+	repoAdded := make(map[string]int)
+	repoRemoved := make(map[string]int)
+	repoFiles := make(map[string]int)
+	changed := 0
+	for _, e := range m.entries {
+		if e.Commit != nil {
+			continue
+		}
+		changed++
+		added, removed := differ.CountChanges(e.Diff)
+		files = append(files, fileStats{
+			path:     m.relPath(e.FilePath),
+			repo:     e.Repo,
+			added:    added,
+			removed:  removed,
+			rewrites: s.rewrites[e.FilePath],
+		})
+		totalAdded += added
+		totalRemoved += removed
+		repoAdded[e.Repo] += added
+		repoRemoved[e.Repo] += removed
+		repoFiles[e.Repo]++
+	}
+
+	var b strings.Builder
+	section := func(title string) {
+		b.WriteString("\n" + CheckTitleStyle.Render("  "+title) + "\n")
+	}
+	counts := func(added, removed int) string {
+		return AddedLineStyle.Render(fmt.Sprintf("+%d", added)) + " " +
+			RemovedLineStyle.Render(fmt.Sprintf("-%d", removed))
+	}
+
+	section("Session")
+	b.WriteString(fmt.Sprintf("  Duration        %s (since %s)\n",
+		time.Since(s.started).Round(time.Second), s.started.Format("15:04:05")))
+	b.WriteString(fmt.Sprintf("  Change events   %d\n", len(s.events)))
+	b.WriteString(fmt.Sprintf("  Files touched   %d during session, %d with changes now\n", len(s.rewrites), changed))
+	b.WriteString("  Lines           " + counts(totalAdded, totalRemoved) + "\n")
+	ws := m.source.Stats()
+	watched := fmt.Sprintf("%d directories", ws.Watched)
+	if ws.Polled > 0 && ws.Backend != watcher.BackendPoll {
+		watched += fmt.Sprintf(" (%d polled)", ws.Polled)
+	}
+	b.WriteString(fmt.Sprintf("  Watcher         %s, %s, %d events\n", ws.Backend, watched, ws.Events))
+
+	section("Churn (changes per minute)")
+	churn := s.churn(max(m.width-20, 10))
+	peak := 0
+	for _, c := range churn {
+		peak = max(peak, c)
+	}
+	b.WriteString("  " + AddedLineStyle.Render(sparkline(churn)) + TimestampStyle.Render(fmt.Sprintf("  peak %d/min", peak)) + "\n")
+
+	if len(repoFiles) > 1 {
+		section("Per repo")
+		var repos []string
+		for r := range repoFiles {
+			repos = append(repos, r)
+		}
+		sort.Strings(repos)
+		for _, r := range repos {
+			b.WriteString(fmt.Sprintf("  %-24s %3d files  %s\n", r, repoFiles[r], counts(repoAdded[r], repoRemoved[r])))
+		}
+	}
+
+	section("Largest changes")
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].added+files[i].removed > files[j].added+files[j].removed
+	})
+	for i, f := range files {
+		if i == statsTopFiles {
+			break
+		}
+		b.WriteString("  " + counts(f.added, f.removed) + "  " + statsPath(f) + "\n")
+	}
+
+	section("Most rewritten")
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].rewrites > files[j].rewrites
+	})
+	listed := 0
+	for _, f := range files {
+		if listed == statsTopFiles || f.rewrites == 0 {
+			break
+		}
+		b.WriteString(fmt.Sprintf("  %4dx  %s\n", f.rewrites, statsPath(f)))
+		listed++
+	}
+	if listed == 0 {
+		b.WriteString(ContextLineStyle.Render("  No changes observed yet") + "\n")
+	}
+
+	return b.String()
+}
+
+func statsPath(f fileStats) string {
+	if f.repo != "" {
+		return RepoTagStyle.Render(f.repo) + " " + f.path
+	}
+	return f.path
+}
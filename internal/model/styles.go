@@ -5,8 +5,7 @@
 // - Tabs, repo tags, and branch indicators
 // - Various text styles (added, removed, context, errors, etc.)
 //
-// The color palette uses a purple/blue theme with hex codes that match
-// the vibewatch aesthetic and provide good contrast and readability.
+// The colors come from a theme.Theme; see ApplyTheme.
 package model
 
 import (
@@ -17,6 +16,8 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/theme"
 )
 
 var (
@@ -25,102 +26,208 @@ var (
 	modelDebugMutex sync.Mutex
 )
 
+// Styles for every UI element. ApplyTheme builds them from a palette.
 var (
-	// Header bar
+	HeaderStyle         lipgloss.Style // Header bar
+	FilePathStyle       lipgloss.Style // File path for each diff entry
+	CommitStyle         lipgloss.Style // Hash and message of a commit entry
+	TimestampStyle      lipgloss.Style
+	AddedLineStyle      lipgloss.Style
+	RemovedLineStyle    lipgloss.Style
+	HunkHeaderStyle     lipgloss.Style
+	ContextLineStyle    lipgloss.Style
+	ErrorStyle          lipgloss.Style
+	AddedWordStyle      lipgloss.Style // Changed words within paired removed/added lines
+	RemovedWordStyle    lipgloss.Style
+	SelectedHunkStyle   lipgloss.Style // Current hunk header of the selected entry
+	StatusBarStyle      lipgloss.Style
+	PausedStyle         lipgloss.Style
+	RepoTagStyle        lipgloss.Style // Repo tag (shown in multi-repo mode)
+	ActiveTabStyle      lipgloss.Style
+	InactiveTabStyle    lipgloss.Style
+	TabWithChangesStyle lipgloss.Style
+	TabBarStyle         lipgloss.Style
+	BranchStyle         lipgloss.Style // Branch name in header (single-repo)
+	BranchLabelStyle    lipgloss.Style // Branch name in tabs (multi-repo)
+	SeparatorStyle      lipgloss.Style
+	HiddenFileStyle     lipgloss.Style
+	ActiveFileStyle     lipgloss.Style
+	FlashStyle          lipgloss.Style // Transient status bar message
+	SinceLookStyle      lipgloss.Style // Label above incremental "since last look" diffs
+	SearchMatchStyle    lipgloss.Style // Content search matches within diff lines
+	TreeDirStyle        lipgloss.Style
+	TreeFileStyle       lipgloss.Style
+	ReviewedStyle       lipgloss.Style // Reviewed check mark on entries and hunks
+	PromptStyle         lipgloss.Style // Input prompt label
+	CommentBadgeStyle   lipgloss.Style // Comment count on an entry header
+	HookPassedStyle     lipgloss.Style
+	HookFailedStyle     lipgloss.Style
+	HookRunningStyle    lipgloss.Style
+	CheckTitleStyle     lipgloss.Style
+	CheckLinkStyle      lipgloss.Style
+	DebugConsoleStyle   lipgloss.Style
+)
+
+func init() {
+	ApplyTheme(theme.Dark)
+}
+
+// ApplyTheme rebuilds all styles from the colors of t.
+func ApplyTheme(t theme.Theme) {
+	c := func(color string) lipgloss.Color { return lipgloss.Color(color) }
+
 	HeaderStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#7D56F4")).
-			Padding(0, 1)
+		Bold(true).
+		Foreground(c(t.HeaderText)).
+		Background(c(t.Header)).
+		Padding(0, 1)
 
-	// File path for each diff entry
 	FilePathStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#FF79C6")).
-			MarginTop(1)
+		Bold(true).
+		Foreground(c(t.Path)).
+		MarginTop(1)
+
+	CommitStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(c(t.Accent)).
+		MarginTop(1)
 
-	// Timestamp
 	TimestampStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#6272A4")).
-			Italic(true)
+		Foreground(c(t.Muted)).
+		Italic(true)
 
 	// Diff lines
-	AddedLineStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B"))
-	RemovedLineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555"))
-	HunkHeaderStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#8BE9FD"))
-	ContextLineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#BFBFBF"))
-	ErrorStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFB86C"))
+	AddedLineStyle = lipgloss.NewStyle().Foreground(c(t.Added))
+	RemovedLineStyle = lipgloss.NewStyle().Foreground(c(t.Removed))
+	HunkHeaderStyle = lipgloss.NewStyle().Foreground(c(t.Info))
+	ContextLineStyle = lipgloss.NewStyle().Foreground(c(t.Context))
+	ErrorStyle = lipgloss.NewStyle().Foreground(c(t.Warning))
+
+	AddedWordStyle = lipgloss.NewStyle().
+		Foreground(c(t.WordText)).
+		Background(c(t.AddedWord)).
+		Bold(true)
+	RemovedWordStyle = lipgloss.NewStyle().
+		Foreground(c(t.WordText)).
+		Background(c(t.RemovedWord)).
+		Bold(true)
+
+	SelectedHunkStyle = lipgloss.NewStyle().
+		Foreground(c(t.Info)).
+		Bold(true)
 
-	// Status bar
 	StatusBarStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#44475A")).
-			Padding(0, 1)
+		Foreground(c(t.StatusText)).
+		Background(c(t.StatusBar)).
+		Padding(0, 1)
 
 	PausedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF5555")).
-			Bold(true)
+		Foreground(c(t.Removed)).
+		Bold(true)
 
-	// Repo tag (shown in multi-repo mode)
 	RepoTagStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#282A36")).
-			Background(lipgloss.Color("#BD93F9")).
-			Padding(0, 1)
+		Bold(true).
+		Foreground(c(t.Background)).
+		Background(c(t.Accent)).
+		Padding(0, 1)
 
 	// Tabs
 	ActiveTabStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#F8F8F2")).
-			Background(lipgloss.Color("#BD93F9")).
-			Padding(0, 1)
+		Bold(true).
+		Foreground(c(t.AccentText)).
+		Background(c(t.Accent)).
+		Padding(0, 1)
 
 	InactiveTabStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#6272A4")).
-				Background(lipgloss.Color("#282A36")).
-				Padding(0, 1)
+		Foreground(c(t.Muted)).
+		Background(c(t.Background)).
+		Padding(0, 1)
 
 	TabWithChangesStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#F8F8F2")).
-				Background(lipgloss.Color("#44475A")).
-				Padding(0, 1)
+		Foreground(c(t.Text)).
+		Background(c(t.Surface)).
+		Padding(0, 1)
 
 	TabBarStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color("#282A36"))
+		Background(c(t.Background))
 
-	// Branch name in header (single-repo)
 	BranchStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#50FA7B")).
-			Bold(true)
+		Foreground(c(t.Added)).
+		Bold(true)
 
-	// Branch name in tabs (multi-repo)
 	BranchLabelStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#8BE9FD")).
-				Italic(true)
+		Foreground(c(t.Info)).
+		Italic(true)
 
-	// Separator
-	SeparatorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+	SeparatorStyle = lipgloss.NewStyle().Foreground(c(t.Muted))
 
-	// Hidden file indicator
 	HiddenFileStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#6272A4")).
-			Italic(true)
+		Foreground(c(t.Muted)).
+		Italic(true)
 
-	// Active file indicator
 	ActiveFileStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#50FA7B")).
-			Bold(true).
-			Background(lipgloss.Color("#282A36")).
-			Padding(0, 1)
+		Foreground(c(t.Added)).
+		Bold(true).
+		Background(c(t.Background)).
+		Padding(0, 1)
+
+	FlashStyle = lipgloss.NewStyle().
+		Foreground(c(t.Highlight))
+
+	SinceLookStyle = lipgloss.NewStyle().
+		Foreground(c(t.Highlight)).
+		Italic(true)
+
+	SearchMatchStyle = lipgloss.NewStyle().
+		Foreground(c(t.Background)).
+		Background(c(t.Highlight)).
+		Bold(true)
+
+	// Tree view
+	TreeDirStyle = lipgloss.NewStyle().
+		Foreground(c(t.Info)).
+		Bold(true)
+	TreeFileStyle = lipgloss.NewStyle().
+		Foreground(c(t.Path))
+
+	ReviewedStyle = lipgloss.NewStyle().
+		Foreground(c(t.Added)).
+		Bold(true)
+
+	PromptStyle = lipgloss.NewStyle().
+		Foreground(c(t.Accent)).
+		Bold(true)
+
+	CommentBadgeStyle = lipgloss.NewStyle().
+		Foreground(c(t.Highlight))
+
+	// Hook results
+	HookPassedStyle = lipgloss.NewStyle().Foreground(c(t.Added))
+	HookFailedStyle = lipgloss.NewStyle().Foreground(c(t.Removed)).Bold(true)
+	HookRunningStyle = lipgloss.NewStyle().Foreground(c(t.Highlight))
+
+	// Checks panel
+	CheckTitleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(c(t.Accent))
+	CheckLinkStyle = lipgloss.NewStyle().
+		Foreground(c(t.Info)).
+		Underline(true)
 
-	// Debug console
 	DebugConsoleStyle = lipgloss.NewStyle().
-				Background(lipgloss.Color("#282A36")).
-				Foreground(lipgloss.Color("#F8F8F2")).
-				Padding(0, 1).
-				BorderStyle(lipgloss.NormalBorder()).
-				BorderForeground(lipgloss.Color("#BD93F9"))
-)
+		Background(c(t.Background)).
+		Foreground(c(t.Text)).
+		Padding(0, 1).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(c(t.Accent))
+
+	helpStyle = lipgloss.NewStyle().
+		Foreground(c(t.Text)).
+		Background(c(t.Background)).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(c(t.Accent))
+}
 
 // logMessage writes a debug message to the model debug file
 func logMessage(message string) {
@@ -0,0 +1,236 @@
+// tree.go renders the alternative view that groups the changed files into a
+// collapsible directory tree with aggregate change counts per repo and directory.
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/types"
+)
+
+// viewMode selects how the entries are presented in the viewport.
+type viewMode int
+
+const (
+	viewList viewMode = iota
+	viewTree
+	viewStats
+)
+
+type treeNode struct {
+	name     string
+	key      string // unique path used to remember collapsed directories
+	depth    int
+	isDir    bool
+	filePath string // absolute path, for file nodes
+	files    int
+	added    int
+	removed  int
+	children []*treeNode
+}
+
+// child returns the directory child with the given name, creating it if needed.
+func (n *treeNode) child(name string) *treeNode {
+	for _, c := range n.children {
+		if c.isDir && c.name == name {
+			return c
+		}
+	}
+	c := &treeNode{name: name, key: n.key + "/" + name, depth: n.depth + 1, isDir: true}
+	n.children = append(n.children, c)
+	return c
+}
+
+func (n *treeNode) sort() {
+	sort.Slice(n.children, func(i, j int) bool {
+		a, b := n.children[i], n.children[j]
+		if a.isDir != b.isDir {
+			return a.isDir
+		}
+		return a.name < b.name
+	})
+	for _, c := range n.children {
+		c.sort()
+	}
+}
+
+// buildTree groups entries by repo and directory, summing counts upwards.
+func (m *Model) buildTree(entries []types.DiffEntry) []*treeNode {
+	root := &treeNode{depth: -1}
+	for _, e := range entries {
+		if e.Commit != nil {
+			continue
+		}
+		repo := e.Repo
+		if repo == "" {
+			repo = filepath.Base(m.dir)
+		}
+		added, removed := differ.CountChanges(e.Diff)
+
+		node := root.child(repo)
+		parts := strings.Split(filepath.ToSlash(m.relPath(e.FilePath)), "/")
+		path := []*treeNode{node}
+		for _, dir := range parts[:len(parts)-1] {
+			node = node.child(dir)
+			path = append(path, node)
+		}
+		name := parts[len(parts)-1]
+		node.children = append(node.children, &treeNode{
+			name:     name,
+			key:      node.key + "/" + name,
+			depth:    node.depth + 1,
+			filePath: e.FilePath,
+			files:    1,
+			added:    added,
+			removed:  removed,
+		})
+		for _, n := range path {
+			n.files++
+			n.added += added
+			n.removed += removed
+		}
+	}
+	root.sort()
+	return root.children
+}
+
+// visibleTreeNodes flattens the tree, skipping children of collapsed directories.
+func (m *Model) visibleTreeNodes() []*treeNode {
+	var nodes []*treeNode
+	var walk func(children []*treeNode)
+	walk = func(children []*treeNode) {
+		for _, n := range children {
+			nodes = append(nodes, n)
+			if n.isDir && !m.collapsedDirs[n.key] {
+				walk(n.children)
+			}
+		}
+	}
+	walk(m.buildTree(m.filteredEntries()))
+	return nodes
+}
+
+func (m *Model) renderTree() string {
+	nodes := m.visibleTreeNodes()
+	if len(nodes) == 0 {
+		return ContextLineStyle.Render("\n  Waiting for file changes...")
+	}
+	m.treeCursor = min(m.treeCursor, len(nodes)-1)
+
+	var b strings.Builder
+	for i, n := range nodes {
+		indent := strings.Repeat("  ", n.depth)
+		counts := AddedLineStyle.Render(fmt.Sprintf("+%d", n.added)) + " " +
+			RemovedLineStyle.Render(fmt.Sprintf("-%d", n.removed))
+
+		var line string
+		switch {
+		case n.depth == 0:
+			line = m.treeArrow(n) + RepoTagStyle.Render(n.name) + " " +
+				TimestampStyle.Render(fmt.Sprintf("%d files", n.files)) + "  " + counts
+		case n.isDir:
+			line = m.treeArrow(n) + TreeDirStyle.Render(n.name+"/") + " " +
+				TimestampStyle.Render(fmt.Sprintf("%d files", n.files)) + "  " + counts
+		default:
+			line = "  " + TreeFileStyle.Render(n.name) + "  " + counts
+		}
+
+		if i == m.treeCursor {
+			b.WriteString(SelectedHunkStyle.Render("▶") + indent + line + "\n")
+		} else {
+			b.WriteString(" " + indent + line + "\n")
+		}
+	}
+	return b.String()
+}
+
+func (m *Model) treeArrow(n *treeNode) string {
+	if m.collapsedDirs[n.key] {
+		return "▸ "
+	}
+	return "▾ "
+}
+
+// updateTree handles navigation keys in the tree view. It reports whether the
+// key was consumed.
+func (m *Model) updateTree(msg tea.KeyMsg) bool {
+	nodes := m.visibleTreeNodes()
+	if len(nodes) == 0 {
+		return false
+	}
+	m.treeCursor = min(m.treeCursor, len(nodes)-1)
+	node := nodes[m.treeCursor]
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.treeCursor = (m.treeCursor - 1 + len(nodes)) % len(nodes)
+	case key.Matches(msg, m.keys.Down):
+		m.treeCursor = (m.treeCursor + 1) % len(nodes)
+	case key.Matches(msg, m.keys.TreeFold):
+		if node.isDir {
+			m.collapsedDirs[node.key] = true
+		}
+	case key.Matches(msg, m.keys.TreeUnfold):
+		if node.isDir {
+			delete(m.collapsedDirs, node.key)
+		}
+	case key.Matches(msg, m.keys.TreeOpen):
+		m.openTreeNode(node)
+		if !node.isDir {
+			return true
+		}
+	default:
+		return false
+	}
+
+	m.viewport.SetContent(m.renderEntries())
+	if m.treeCursor < m.viewport.YOffset {
+		m.viewport.SetYOffset(m.treeCursor)
+	} else if m.treeCursor >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(m.treeCursor - m.viewport.Height + 1)
+	}
+	return true
+}
+
+// openTreeNode folds or unfolds a directory, or opens a file in the list view.
+func (m *Model) openTreeNode(node *treeNode) {
+	if !node.isDir {
+		m.viewMode = viewList
+		m.selectFile(node.filePath)
+		return
+	}
+	if m.collapsedDirs[node.key] {
+		delete(m.collapsedDirs, node.key)
+	} else {
+		m.collapsedDirs[node.key] = true
+	}
+	m.viewport.SetContent(m.renderEntries())
+}
+
+// toggleViewMode switches between the list and tree views, keeping the
+// selected file under the cursor.
+func (m *Model) toggleViewMode() {
+	if m.viewMode == viewTree {
+		m.viewMode = viewList
+		m.ensureSelectedFileVisible()
+		return
+	}
+
+	m.viewMode = viewTree
+	m.treeCursor = 0
+	for i, n := range m.visibleTreeNodes() {
+		if !n.isDir && n.filePath == m.selectedFilePath {
+			m.treeCursor = i
+			break
+		}
+	}
+	m.viewport.SetContent(m.renderEntries())
+	m.viewport.SetYOffset(max(m.treeCursor-m.viewport.Height/2, 0))
+}
@@ -0,0 +1,173 @@
+// worddiff.go highlights the exact words that changed between paired removed
+// and added lines, so a one-identifier edit in a long line stands out.
+package model
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+)
+
+const (
+	// maxWordDiffTokens bounds the cost of the token comparison per line pair.
+	maxWordDiffTokens = 300
+	// maxChangedRatio skips highlighting when most of the line changed, since
+	// emphasizing everything would hide rather than show the difference.
+	maxChangedRatio = 0.6
+)
+
+// span is a byte range within a line.
+type span struct {
+	start, end int
+}
+
+// wordDiffSpans pairs each run of removed lines with the run of added lines
+// that follows it and returns, by line index, the ranges that differ. The
+// lines are a whole unified diff; file headers are never paired.
+func wordDiffSpans(lines []string) map[int][]span {
+	result := make(map[int][]span)
+	header := differ.HeaderLines(lines)
+	isBody := func(i int, marker byte) bool {
+		return !header[i] && len(lines[i]) > 0 && lines[i][0] == marker
+	}
+
+	for i := 0; i < len(lines); {
+		if !isBody(i, '-') {
+			i++
+			continue
+		}
+		removedStart := i
+		for i < len(lines) && isBody(i, '-') {
+			i++
+		}
+		addedStart := i
+		for i < len(lines) && isBody(i, '+') {
+			i++
+		}
+		pairs := min(addedStart-removedStart, i-addedStart)
+		for p := 0; p < pairs; p++ {
+			ri, ai := removedStart+p, addedStart+p
+			oldSpans, newSpans, ok := changedSpans(lines[ri][1:], lines[ai][1:])
+			if !ok {
+				continue
+			}
+			result[ri] = shiftSpans(oldSpans, 1)
+			result[ai] = shiftSpans(newSpans, 1)
+		}
+	}
+	return result
+}
+
+func shiftSpans(spans []span, offset int) []span {
+	for i := range spans {
+		spans[i].start += offset
+		spans[i].end += offset
+	}
+	return spans
+}
+
+// tokenize splits a line into words, whitespace runs and single punctuation
+// characters, returning the byte offset where each token starts.
+func tokenize(s string) ([]string, []int) {
+	var tokens []string
+	var offsets []int
+	kind := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		}
+		return 0
+	}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		start := i
+		i += size
+		if k := kind(r); k != 0 {
+			for i < len(s) {
+				next, nextSize := utf8.DecodeRuneInString(s[i:])
+				if kind(next) != k {
+					break
+				}
+				i += nextSize
+			}
+		}
+		tokens = append(tokens, s[start:i])
+		offsets = append(offsets, start)
+	}
+	return tokens, offsets
+}
+
+// changedSpans compares two lines token by token and returns the ranges of
+// each that are not part of their longest common subsequence. It reports
+// false when the lines are too long or too different to highlight usefully.
+func changedSpans(old, new string) ([]span, []span, bool) {
+	a, aOff := tokenize(old)
+	b, bOff := tokenize(new)
+	if len(a) == 0 || len(b) == 0 || len(a) > maxWordDiffTokens || len(b) > maxWordDiffTokens {
+		return nil, nil, false
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	keepA := make([]bool, len(a))
+	keepB := make([]bool, len(b))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			keepA[i], keepB[j] = true, true
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	oldSpans, oldChanged := collectSpans(a, aOff, keepA)
+	newSpans, newChanged := collectSpans(b, bOff, keepB)
+	if oldChanged == 0 && newChanged == 0 {
+		return nil, nil, false
+	}
+	if float64(oldChanged) > maxChangedRatio*float64(len(old)) ||
+		float64(newChanged) > maxChangedRatio*float64(len(new)) {
+		return nil, nil, false
+	}
+	return oldSpans, newSpans, true
+}
+
+// collectSpans merges adjacent changed tokens into spans and returns them
+// with the total number of changed bytes.
+func collectSpans(tokens []string, offsets []int, keep []bool) ([]span, int) {
+	var spans []span
+	changed := 0
+	for i, tok := range tokens {
+		if keep[i] {
+			continue
+		}
+		start, end := offsets[i], offsets[i]+len(tok)
+		changed += len(tok)
+		if n := len(spans); n > 0 && spans[n-1].end == start {
+			spans[n-1].end = end
+		} else {
+			spans = append(spans, span{start, end})
+		}
+	}
+	return spans, changed
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWordDiffSpans(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  map[int][]span
+	}{
+		{
+			name:  "one word changed",
+			lines: []string{"@@ -1 +1 @@", "-foo bar", "+foo baz"},
+			want:  map[int][]span{1: {{5, 8}}, 2: {{5, 8}}},
+		},
+		{
+			name:  "operator changed",
+			lines: []string{"@@ -1 +1 @@", "-return a + b", "+return a - b"},
+			want:  map[int][]span{1: {{10, 11}}, 2: {{10, 11}}},
+		},
+		{
+			name: "runs of unequal length pair in order",
+			lines: []string{
+				"@@ -1,3 +1,2 @@",
+				"-alpha one",
+				"-beta two",
+				"+alpha uno",
+				" context",
+			},
+			want: map[int][]span{1: {{7, 10}}, 3: {{7, 10}}},
+		},
+		{
+			name: "separate runs pair separately",
+			lines: []string{
+				"@@ -1,3 +1,3 @@",
+				"-x = 1",
+				"+x = 2",
+				" keep",
+				"-y = 3",
+				"+y = 4",
+			},
+			want: map[int][]span{1: {{5, 6}}, 2: {{5, 6}}, 4: {{5, 6}}, 5: {{5, 6}}},
+		},
+		{
+			name: "file headers are not paired",
+			lines: []string{
+				"diff --git a/a.txt b/a.txt",
+				"--- a/a.txt",
+				"+++ b/a.txt",
+				"@@ -1 +1 @@",
+				"-foo bar",
+				"+foo baz",
+			},
+			want: map[int][]span{4: {{5, 8}}, 5: {{5, 8}}},
+		},
+		{
+			name:  "body lines that look like headers",
+			lines: []string{"@@ -1 +1 @@", "--- old note", "+-- new note"},
+			want:  map[int][]span{1: {{4, 7}}, 2: {{4, 7}}},
+		},
+		{
+			name:  "too different",
+			lines: []string{"@@ -1 +1 @@", "-abc", "+xyz"},
+			want:  map[int][]span{},
+		},
+		{
+			name:  "unchanged",
+			lines: []string{"@@ -1 +1 @@", "-same", "+same"},
+			want:  map[int][]span{},
+		},
+		{
+			name:  "added only",
+			lines: []string{"@@ -0,0 +1 @@", "+new"},
+			want:  map[int][]span{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wordDiffSpans(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wordDiffSpans() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens, offsets := tokenize("f(a_1,  b)")
+	wantTokens := []string{"f", "(", "a_1", ",", "  ", "b", ")"}
+	wantOffsets := []int{0, 1, 2, 5, 6, 8, 9}
+	if !reflect.DeepEqual(tokens, wantTokens) || !reflect.DeepEqual(offsets, wantOffsets) {
+		t.Errorf("tokenize() = %q %v, want %q %v", tokens, offsets, wantTokens, wantOffsets)
+	}
+}
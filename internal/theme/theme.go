@@ -0,0 +1,234 @@
+// Package theme defines the color palettes vibewatch can render with.
+package theme
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Auto picks the dark or light theme from the terminal background.
+const Auto = "auto"
+
+// Theme is a palette of colors. Colors are hex codes ("#RRGGBB" or "#RGB") or
+// ANSI color numbers ("0" to "255").
+type Theme struct {
+	// Base names the built-in theme a custom theme starts from; only the
+	// colors it sets are overridden.
+	Base string `json:"base,omitempty"`
+
+	Text        string `json:"text,omitempty"`
+	Background  string `json:"background,omitempty"`
+	Header      string `json:"header,omitempty"`
+	HeaderText  string `json:"header_text,omitempty"`
+	StatusBar   string `json:"status_bar,omitempty"`
+	StatusText  string `json:"status_text,omitempty"`
+	Surface     string `json:"surface,omitempty"`
+	Accent      string `json:"accent,omitempty"`
+	AccentText  string `json:"accent_text,omitempty"`
+	Muted       string `json:"muted,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Added       string `json:"added,omitempty"`
+	Removed     string `json:"removed,omitempty"`
+	AddedWord   string `json:"added_word,omitempty"`
+	RemovedWord string `json:"removed_word,omitempty"`
+	WordText    string `json:"word_text,omitempty"`
+	Context     string `json:"context,omitempty"`
+	Info        string `json:"info,omitempty"`
+	Warning     string `json:"warning,omitempty"`
+	Highlight   string `json:"highlight,omitempty"`
+}
+
+// Dark is the default palette, based on Dracula.
+var Dark = Theme{
+	Text:        "#F8F8F2",
+	Background:  "#282A36",
+	Header:      "#7D56F4",
+	HeaderText:  "#FFFFFF",
+	StatusBar:   "#44475A",
+	StatusText:  "#FFFFFF",
+	Surface:     "#44475A",
+	Accent:      "#BD93F9",
+	AccentText:  "#F8F8F2",
+	Muted:       "#6272A4",
+	Path:        "#FF79C6",
+	Added:       "#50FA7B",
+	Removed:     "#FF5555",
+	AddedWord:   "#2F6F3F",
+	RemovedWord: "#8B2F2F",
+	WordText:    "#F8F8F2",
+	Context:     "#BFBFBF",
+	Info:        "#8BE9FD",
+	Warning:     "#FFB86C",
+	Highlight:   "#F1FA8C",
+}
+
+// Light is readable on white and light gray terminal backgrounds.
+var Light = Theme{
+	Text:        "#24292F",
+	Background:  "#F6F8FA",
+	Header:      "#6639BA",
+	HeaderText:  "#FFFFFF",
+	StatusBar:   "#D0D7DE",
+	StatusText:  "#24292F",
+	Surface:     "#D0D7DE",
+	Accent:      "#8250DF",
+	AccentText:  "#FFFFFF",
+	Muted:       "#57606A",
+	Path:        "#BF3989",
+	Added:       "#1A7F37",
+	Removed:     "#CF222E",
+	AddedWord:   "#ABF2BC",
+	RemovedWord: "#FFCECB",
+	WordText:    "#24292F",
+	Context:     "#3D444D",
+	Info:        "#0969DA",
+	Warning:     "#BC4C00",
+	Highlight:   "#9A6700",
+}
+
+// HighContrast uses pure, saturated colors on black.
+var HighContrast = Theme{
+	Text:        "#FFFFFF",
+	Background:  "#000000",
+	Header:      "#5F00D7",
+	HeaderText:  "#FFFFFF",
+	StatusBar:   "#303030",
+	StatusText:  "#FFFFFF",
+	Surface:     "#303030",
+	Accent:      "#FFFF00",
+	AccentText:  "#000000",
+	Muted:       "#C0C0C0",
+	Path:        "#FF87FF",
+	Added:       "#00FF00",
+	Removed:     "#FF3030",
+	AddedWord:   "#006400",
+	RemovedWord: "#8B0000",
+	WordText:    "#FFFFFF",
+	Context:     "#FFFFFF",
+	Info:        "#00FFFF",
+	Warning:     "#FFAF00",
+	Highlight:   "#FFFF00",
+}
+
+// Colorblind replaces green/red with blue/orange, which stay distinct with
+// the common forms of color blindness.
+var Colorblind = Theme{
+	Text:        "#F8F8F2",
+	Background:  "#282A36",
+	Header:      "#7D56F4",
+	HeaderText:  "#FFFFFF",
+	StatusBar:   "#44475A",
+	StatusText:  "#FFFFFF",
+	Surface:     "#44475A",
+	Accent:      "#BD93F9",
+	AccentText:  "#F8F8F2",
+	Muted:       "#8C96B8",
+	Path:        "#CC79A7",
+	Added:       "#56B4E9",
+	Removed:     "#E69F00",
+	AddedWord:   "#1F4E79",
+	RemovedWord: "#7A4A00",
+	WordText:    "#F8F8F2",
+	Context:     "#BFBFBF",
+	Info:        "#8BE9FD",
+	Warning:     "#F0E442",
+	Highlight:   "#F0E442",
+}
+
+// builtins maps the names accepted in the config file to built-in themes.
+var builtins = map[string]Theme{
+	"dark":          Dark,
+	"light":         Light,
+	"high-contrast": HighContrast,
+	"colorblind":    Colorblind,
+}
+
+// Names returns the names of the built-in themes.
+func Names() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Detect returns "dark" or "light" depending on the terminal background. It
+// queries the terminal, so call it before the UI takes over the screen.
+func Detect() string {
+	if lipgloss.HasDarkBackground() {
+		return "dark"
+	}
+	return "light"
+}
+
+// Resolve returns the theme called name: "auto" (or empty) for the detected
+// default, a built-in, or one of the custom themes from the config file.
+// Custom themes start from their base, or from the built-in of the same name.
+func Resolve(name string, custom map[string]Theme) (Theme, error) {
+	if name == "" || name == Auto {
+		name = Detect()
+	}
+
+	c, ok := custom[name]
+	if !ok {
+		t, ok := builtins[name]
+		if !ok {
+			return Theme{}, fmt.Errorf("unknown theme %q (built-in themes: %s)", name, strings.Join(Names(), ", "))
+		}
+		return t, nil
+	}
+
+	baseName := c.Base
+	if baseName == "" {
+		baseName = name
+		if _, ok := builtins[name]; !ok {
+			baseName = Detect()
+		}
+	}
+	if baseName == Auto {
+		baseName = Detect()
+	}
+	t, ok := builtins[baseName]
+	if !ok {
+		return Theme{}, fmt.Errorf("theme %q: unknown base %q", name, c.Base)
+	}
+
+	dst, src := t.colors(), c.colors()
+	for i, color := range src {
+		if *color == "" {
+			continue
+		}
+		if !validColor(*color) {
+			return Theme{}, fmt.Errorf("theme %q: invalid color %q", name, *color)
+		}
+		*dst[i] = *color
+	}
+	return t, nil
+}
+
+// colors returns pointers to every color of t, in declaration order.
+func (t *Theme) colors() []*string {
+	return []*string{
+		&t.Text, &t.Background, &t.Header, &t.HeaderText, &t.StatusBar,
+		&t.StatusText, &t.Surface, &t.Accent, &t.AccentText, &t.Muted,
+		&t.Path, &t.Added, &t.Removed, &t.AddedWord, &t.RemovedWord,
+		&t.WordText, &t.Context, &t.Info, &t.Warning, &t.Highlight,
+	}
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$`)
+
+// validColor reports whether c is a hex code or an ANSI color number.
+func validColor(c string) bool {
+	if hexColor.MatchString(c) {
+		return true
+	}
+	n, err := strconv.Atoi(c)
+	return err == nil && n >= 0 && n <= 255
+}
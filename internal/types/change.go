@@ -10,5 +10,15 @@ type DiffEntry struct {
 	Diff      string // raw unified diff text
 	IsNew     bool
 	IsDeleted bool
-	Error     string // non-fatal error message
+	Error     string  // non-fatal error message
+	Commit    *Commit // set for commits made during the session; Diff is the commit's diff
+}
+
+// Commit describes a commit that landed while vibewatch was running. Its
+// timeline entry uses the full hash as FilePath.
+type Commit struct {
+	Hash    string
+	Message string // subject line
+	Author  string
+	Files   []string // paths relative to the repo root
 }
@@ -0,0 +1,58 @@
+package watcher
+
+import (
+	"errors"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Backend names accepted by Options.Backend.
+const (
+	BackendAuto     = "auto"
+	BackendFsnotify = "fsnotify"
+	BackendPoll     = "poll"
+)
+
+// backend is a source of raw filesystem events. Directories are added one at
+// a time; the Watcher walks the tree and adds new directories as they appear.
+type backend interface {
+	Add(dir string) error
+	Events() <-chan fsnotify.Event
+	Errors() <-chan error
+	Close() error
+}
+
+// fsnotifyBackend delivers native filesystem notifications.
+type fsnotifyBackend struct {
+	fsw *fsnotify.Watcher
+}
+
+func newFsnotifyBackend() (*fsnotifyBackend, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &fsnotifyBackend{fsw: fsw}, nil
+}
+
+func (b *fsnotifyBackend) Add(dir string) error          { return b.fsw.Add(dir) }
+func (b *fsnotifyBackend) Events() <-chan fsnotify.Event { return b.fsw.Events }
+func (b *fsnotifyBackend) Errors() <-chan error          { return b.fsw.Errors }
+func (b *fsnotifyBackend) Close() error                  { return b.fsw.Close() }
+
+// Errors reported by a Watcher.
+var (
+	// ErrOverflow means the OS dropped events, so changes may have been missed
+	// and the whole tree should be rescanned.
+	ErrOverflow = errors.New("too many changes at once; some events were lost")
+	// ErrWatchLimit means the OS has no watches left, for example because
+	// fs.inotify.max_user_watches is too low for the tree.
+	ErrWatchLimit = errors.New("watch limit reached")
+)
+
+// isWatchLimit reports whether err means the OS ran out of watches, such as
+// inotify's max_user_watches (ENOSPC) or kqueue's file descriptors (EMFILE).
+func isWatchLimit(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
+}
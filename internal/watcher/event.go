@@ -0,0 +1,78 @@
+package watcher
+
+import "time"
+
+// Op is the kind of change an Event reports.
+type Op int
+
+const (
+	OpCreate Op = iota + 1
+	OpWrite
+	OpRemove
+	OpRename
+	// OpGit means the repository's state changed (HEAD, the index or a reflog), for
+	// example after a commit, checkout or reset; Event.Git says which.
+	OpGit
+	// OpRepoAdd and OpRepoRemove mean a repository appeared or disappeared
+	// under the root; Path is its root. See Options.DiscoverRepos.
+	OpRepoAdd
+	OpRepoRemove
+)
+
+func (op Op) String() string {
+	switch op {
+	case OpCreate:
+		return "create"
+	case OpWrite:
+		return "write"
+	case OpRemove:
+		return "remove"
+	case OpRename:
+		return "rename"
+	case OpGit:
+		return "git"
+	case OpRepoAdd:
+		return "repo add"
+	case OpRepoRemove:
+		return "repo remove"
+	}
+	return "unknown"
+}
+
+// IsFile reports whether op is a change to a file rather than to a repository.
+func (op Op) IsFile() bool {
+	return op >= OpCreate && op <= OpRename
+}
+
+// Event is a change to a watched path.
+type Event struct {
+	Path string
+	Op   Op
+	Time time.Time
+	// Repo is the name of the repository containing Path, or "" when the
+	// path is outside every known repository.
+	Repo string
+	// Git describes the operation for OpGit events.
+	Git GitOperation
+}
+
+// Stats summarizes the activity of a Source.
+type Stats struct {
+	Backend   string // e.g. BackendFsnotify or BackendPoll
+	Watched   int    // directories being watched
+	Polled    int    // of those, directories polled because the watch limit was hit
+	Events    int    // events delivered so far
+	LastEvent time.Time
+}
+
+// Source is a stream of change events. The filesystem Watcher is one; others
+// could replay a recorded session or relay events reported by an agent.
+type Source interface {
+	// Events returns the channel events are delivered on.
+	Events() <-chan Event
+	// Errors reports problems that may mean changes were missed; ErrOverflow
+	// asks for a full rescan.
+	Errors() <-chan error
+	Close() error
+	Stats() Stats
+}
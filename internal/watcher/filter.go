@@ -4,7 +4,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 
 	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
 )
@@ -22,6 +25,7 @@ var builtinIgnores = []string{
 	".mypy_cache",
 	".pytest_cache",
 	".DS_Store",
+	".vibewatch",
 	".swp",
 	".swo",
 	"~",
@@ -42,25 +46,70 @@ var ignoredExtensions = []string{
 
 // Filter decides which paths should be ignored by the watcher.
 type Filter struct {
-	root      string
-	repoRoots []string
+	root        string
+	mu          sync.RWMutex
+	repoRoots   []string
+	ignorePaths map[string]bool
 }
 
 // NewFilter creates a filter that respects each repo's .gitignore and built-in exclusions.
 func NewFilter(root string, repoRoots []string) *Filter {
-	return &Filter{root: root, repoRoots: repoRoots}
+	return &Filter{root: root, repoRoots: repoRoots, ignorePaths: make(map[string]bool)}
+}
+
+// IgnorePath excludes a specific file, such as one vibewatch writes itself.
+func (f *Filter) IgnorePath(path string) {
+	f.ignorePaths[path] = true
+}
+
+// AddRepoRoot starts treating root as a repository, so its .gitignore applies.
+func (f *Filter) AddRepoRoot(root string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	roots := append([]string{root}, f.repoRoots...)
+	// Longest first, as FindRepoRoot expects
+	sort.SliceStable(roots, func(i, j int) bool { return len(roots[i]) > len(roots[j]) })
+	f.repoRoots = roots
+}
+
+// RemoveRepoRoot forgets a repository that was deleted.
+func (f *Filter) RemoveRepoRoot(root string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	roots := make([]string, 0, len(f.repoRoots))
+	for _, r := range f.repoRoots {
+		if r != root {
+			roots = append(roots, r)
+		}
+	}
+	f.repoRoots = roots
+}
+
+// repoRoot returns the root of the known repository containing path, or "".
+func (f *Filter) repoRoot(path string) string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return differ.FindRepoRoot(path, f.repoRoots)
+}
+
+// isRepoRoot reports whether dir is the root of a known repository.
+func (f *Filter) isRepoRoot(dir string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return slices.Contains(f.repoRoots, dir)
 }
 
 // ShouldIgnore returns true if the path should be excluded from watching.
 func (f *Filter) ShouldIgnore(path string) bool {
+	if f.ignorePaths[path] {
+		return true
+	}
+
 	base := filepath.Base(path)
 
 	if base == ".git" {
 		return false
 	}
-	if (base == "HEAD" || base == "index") && strings.Contains(path, ".git") {
-		return false
-	}
 
 	if strings.HasSuffix(path, ".exe") || strings.HasSuffix(path, ".so") ||
 		strings.HasSuffix(path, ".dylib") || strings.HasSuffix(path, ".a") ||
@@ -107,7 +156,7 @@ func (f *Filter) ShouldIgnore(path string) bool {
 		}
 	}
 
-	repoRoot := differ.FindRepoRoot(path, f.repoRoots)
+	repoRoot := f.repoRoot(path)
 	if repoRoot != "" {
 		if differ.IsGitIgnored(repoRoot, path) {
 			return true
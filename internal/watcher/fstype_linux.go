@@ -0,0 +1,108 @@
+//go:build linux
+
+package watcher
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// fuseMagic is the statfs(2) magic number shared by every FUSE filesystem.
+const fuseMagic = 0x65735546
+
+// remoteFSTypes are statfs(2) magic numbers of filesystems whose changes made
+// elsewhere (on the server or the host) never reach inotify.
+var remoteFSTypes = map[uint32]string{
+	0x6969:     "nfs",
+	0x517b:     "smb",
+	0xff534d42: "cifs",
+	0xfe534d42: "smb2",
+	0x01021997: "9p",
+	0x6a656a63: "virtiofs",
+}
+
+// networkFUSE are the FUSE subtypes that serve files from another machine.
+// Other FUSE filesystems, such as encrypted home directories or overlays, are
+// local and get inotify events like any other.
+var networkFUSE = map[string]bool{
+	"sshfs":     true,
+	"curlftpfs": true,
+	"s3fs":      true,
+	"gcsfuse":   true,
+	"smbnetfs":  true,
+	"glusterfs": true,
+}
+
+// remoteFSType returns the name of the remote filesystem path is on, or ""
+// for local filesystems.
+func remoteFSType(path string) string {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return ""
+	}
+	if uint32(st.Type) == fuseMagic {
+		f, err := os.Open("/proc/self/mountinfo")
+		if err != nil {
+			return ""
+		}
+		defer f.Close()
+		if subtype := fuseSubtype(f, path); networkFUSE[subtype] {
+			return "fuse." + subtype
+		}
+		return ""
+	}
+	return remoteFSTypes[uint32(st.Type)]
+}
+
+// fuseSubtype returns the FUSE subtype ("sshfs" for "fuse.sshfs") of the
+// mount containing path, read from a mountinfo(5) table.
+func fuseSubtype(mountinfo io.Reader, path string) string {
+	var mountPoint, fsType string
+	scanner := bufio.NewScanner(mountinfo)
+	for scanner.Scan() {
+		// "36 35 98:0 /root /mnt rw master:1 - fuse.sshfs host:/dir rw"
+		mount, super, ok := strings.Cut(scanner.Text(), " - ")
+		if !ok {
+			continue
+		}
+		fields, superFields := strings.Fields(mount), strings.Fields(super)
+		if len(fields) < 5 || len(superFields) < 1 {
+			continue
+		}
+		point := unescapeMountPath(fields[4])
+		if !containsPath(point, path) || len(point) < len(mountPoint) {
+			continue
+		}
+		mountPoint, fsType = point, superFields[0]
+	}
+	return strings.TrimPrefix(fsType, "fuse.")
+}
+
+// containsPath reports whether path is the mount point dir or below it.
+func containsPath(dir, path string) bool {
+	return dir == "/" || path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+// unescapeMountPath decodes the octal escapes (\040 for a space) that
+// mountinfo uses in paths.
+func unescapeMountPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
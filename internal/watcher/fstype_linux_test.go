@@ -0,0 +1,37 @@
+//go:build linux
+
+package watcher
+
+import (
+	"strings"
+	"testing"
+)
+
+const testMountinfo = `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+40 22 0:35 / /home/ada rw,nosuid shared:20 - fuse.gocryptfs /home/.ada rw,user_id=1000
+41 22 0:36 / /mnt/server rw,nosuid shared:21 - fuse.sshfs ada@server:/srv rw,user_id=1000
+42 41 0:37 / /mnt/server/cache rw,nosuid shared:22 - fuse.rclone cache: rw,user_id=1000
+43 22 0:38 / /mnt/my\040share rw,nosuid shared:23 - fuse.sshfs ada@nas:/share rw,user_id=1000
+`
+
+func TestFuseSubtype(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/home/ada/project", "gocryptfs"},
+		{"/mnt/server", "sshfs"},
+		{"/mnt/server/repo", "sshfs"},
+		{"/mnt/server/cache/repo", "rclone"},
+		{"/mnt/serverless", "ext4"},
+		{"/mnt/my share/repo", "sshfs"},
+		{"/srv/repo", "ext4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := fuseSubtype(strings.NewReader(testMountinfo), tt.path); got != tt.want {
+				t.Errorf("fuseSubtype(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package watcher
+
+// remoteFSType is only implemented on Linux; elsewhere auto mode relies on the
+// native backend failing to start.
+func remoteFSType(path string) string {
+	return ""
+}
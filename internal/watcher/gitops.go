@@ -0,0 +1,309 @@
+package watcher
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// GitOpKind is the kind of git operation behind an OpGit event.
+type GitOpKind int
+
+const (
+	// GitIndex means only the index changed, e.g. after git add.
+	GitIndex GitOpKind = iota
+	GitCommit
+	GitCheckout
+	GitReset
+	GitStash
+	GitMerge
+	GitRebaseStart
+	GitRebase // a step of a rebase in progress
+	GitRebaseEnd
+	// GitOther is a HEAD movement vibewatch does not recognize.
+	GitOther
+)
+
+func (k GitOpKind) String() string {
+	switch k {
+	case GitIndex:
+		return "index"
+	case GitCommit:
+		return "commit"
+	case GitCheckout:
+		return "checkout"
+	case GitReset:
+		return "reset"
+	case GitStash:
+		return "stash"
+	case GitMerge:
+		return "merge"
+	case GitRebaseStart:
+		return "rebase start"
+	case GitRebase:
+		return "rebase"
+	case GitRebaseEnd:
+		return "rebase end"
+	}
+	return "other"
+}
+
+// GitOperation describes what happened in a repository.
+type GitOperation struct {
+	Kind GitOpKind
+	// Message is the reflog message, e.g. "commit: Fix parser" or
+	// "checkout: moving from main to feature".
+	Message string
+	// Commit is the hash HEAD points to after the operation, when it moved.
+	Commit string
+	// Author is the identity recorded in the reflog.
+	Author string
+	// Submodule is the path of the submodule the operation happened in,
+	// relative to the repository root, or "" for the repository itself.
+	Submodule string
+}
+
+// gitStatePaths are the files directly inside a git directory whose changes
+// signal a git operation.
+var gitStatePaths = map[string]bool{
+	"HEAD":         true,
+	"index":        true,
+	"ORIG_HEAD":    true,
+	"MERGE_HEAD":   true,
+	"rebase-merge": true,
+	"rebase-apply": true,
+}
+
+// stateGitDir returns the git directory path would be a state file of: one of
+// the gitStatePaths or the HEAD reflog (logs/HEAD). It returns "" for other
+// paths.
+func stateGitDir(path string) string {
+	dir, base := filepath.Split(path)
+	dir = filepath.Clean(dir)
+	if gitStatePaths[base] {
+		return dir
+	}
+	if base == "HEAD" && filepath.Base(dir) == "logs" {
+		return filepath.Dir(dir)
+	}
+	return ""
+}
+
+// resolveGitDir returns the git directory a .git entry stands for. A
+// directory is the git directory itself; worktrees and submodules have a file
+// instead, reading "gitdir: <path>".
+func resolveGitDir(dotGit string) string {
+	info, err := os.Stat(dotGit)
+	if err != nil || info.IsDir() {
+		return dotGit
+	}
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return ""
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	dir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(dotGit), dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// commonGitDir returns the directory holding the refs shared by all worktrees
+// of a repository, such as the stash.
+func commonGitDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	dir := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// gitState is the repository state compared before and after an operation.
+type gitState struct {
+	head       string // contents of HEAD
+	reflogSize int64  // size of logs/HEAD
+	stashSize  int64  // size of logs/refs/stash
+	merging    bool   // MERGE_HEAD exists
+	rebasing   bool   // rebase-merge or rebase-apply exists
+}
+
+func readGitState(gitDir string) gitState {
+	var st gitState
+	if data, err := os.ReadFile(filepath.Join(gitDir, "HEAD")); err == nil {
+		st.head = strings.TrimSpace(string(data))
+	}
+	if info, err := os.Stat(filepath.Join(gitDir, "logs", "HEAD")); err == nil {
+		st.reflogSize = info.Size()
+	}
+	if info, err := os.Stat(stashLog(gitDir)); err == nil {
+		st.stashSize = info.Size()
+	}
+	st.merging = exists(filepath.Join(gitDir, "MERGE_HEAD"))
+	st.rebasing = exists(filepath.Join(gitDir, "rebase-merge")) || exists(filepath.Join(gitDir, "rebase-apply"))
+	return st
+}
+
+func stashLog(gitDir string) string {
+	return filepath.Join(commonGitDir(gitDir), "logs", "refs", "stash")
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// gitTracker remembers the state of each repository so that a change can be
+// classified by comparing it with the state before.
+type gitTracker struct {
+	mu     sync.Mutex
+	states map[string]gitState // by git directory
+	roots  map[string]string   // git directory -> working tree root
+}
+
+func newGitTracker() *gitTracker {
+	return &gitTracker{states: make(map[string]gitState), roots: make(map[string]string)}
+}
+
+// track records the current state of gitDir, the git directory of the working
+// tree at root, as the baseline.
+func (t *gitTracker) track(gitDir, root string) {
+	t.mu.Lock()
+	t.states[gitDir] = readGitState(gitDir)
+	t.roots[gitDir] = root
+	t.mu.Unlock()
+}
+
+// rootOf returns the working tree root of a tracked git directory.
+func (t *gitTracker) rootOf(gitDir string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	root, ok := t.roots[gitDir]
+	return root, ok
+}
+
+// forget stops tracking the git directory of a deleted working tree.
+func (t *gitTracker) forget(root string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for gitDir, r := range t.roots {
+		if r == root {
+			delete(t.states, gitDir)
+			delete(t.roots, gitDir)
+		}
+	}
+}
+
+// classify works out which operation changed gitDir since the last call.
+func (t *gitTracker) classify(gitDir string) GitOperation {
+	cur := readGitState(gitDir)
+	t.mu.Lock()
+	prev, known := t.states[gitDir]
+	t.states[gitDir] = cur
+	t.mu.Unlock()
+	if !known {
+		prev = cur
+	}
+
+	// The newest HEAD reflog entry says what moved HEAD, if anything did
+	var op GitOperation
+	moved := cur.reflogSize > prev.reflogSize
+	if moved {
+		op = parseReflogEntry(lastLine(filepath.Join(gitDir, "logs", "HEAD"), prev.reflogSize))
+	}
+
+	switch {
+	case !prev.rebasing && cur.rebasing:
+		op.Kind = GitRebaseStart
+	case prev.rebasing && !cur.rebasing:
+		op.Kind = GitRebaseEnd
+	case cur.stashSize > prev.stashSize:
+		// git stash also resets HEAD, which shows up in the reflog
+		op = GitOperation{Kind: GitStash, Message: lastLineMessage(stashLog(gitDir), prev.stashSize)}
+	case moved:
+	case cur.stashSize < prev.stashSize:
+		op = GitOperation{Kind: GitStash, Message: "stash popped or dropped"}
+	case !prev.merging && cur.merging:
+		op = GitOperation{Kind: GitMerge, Message: "merge: stopped with conflicts"}
+	case cur.head != prev.head:
+		op = GitOperation{Kind: GitCheckout, Message: "checkout: " + strings.TrimPrefix(cur.head, "ref: refs/heads/")}
+	default:
+		op = GitOperation{Kind: GitIndex}
+	}
+	return op
+}
+
+// lastLine returns the last line of the file written after offset.
+func lastLine(path string, offset int64) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return ""
+	}
+	var last string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			last = line
+		}
+	}
+	return last
+}
+
+func lastLineMessage(path string, offset int64) string {
+	return parseReflogEntry(lastLine(path, offset)).Message
+}
+
+// parseReflogEntry parses a reflog line:
+// "<old> <new> <name> <<email>> <time> <tz>\t<message>".
+func parseReflogEntry(line string) GitOperation {
+	header, message, _ := strings.Cut(line, "\t")
+	op := GitOperation{Kind: GitOther, Message: message}
+
+	// header is "<old> <new> <identity>"
+	parts := strings.SplitN(header, " ", 3)
+	if len(parts) == 3 {
+		op.Commit = parts[1]
+		if name, _, ok := strings.Cut(parts[2], "<"); ok {
+			op.Author = strings.TrimSpace(name)
+		}
+	}
+
+	action, _, _ := strings.Cut(message, ":")
+	switch {
+	case action == "commit (merge)",
+		strings.HasPrefix(action, "merge"),
+		strings.HasPrefix(action, "pull"):
+		op.Kind = GitMerge
+	case strings.HasPrefix(action, "commit"),
+		strings.HasPrefix(action, "cherry-pick"),
+		strings.HasPrefix(action, "revert"),
+		strings.HasPrefix(action, "am"):
+		op.Kind = GitCommit
+	case strings.HasPrefix(action, "checkout"), strings.HasPrefix(action, "switch"):
+		op.Kind = GitCheckout
+	case strings.HasPrefix(action, "reset"):
+		op.Kind = GitReset
+	case strings.HasPrefix(action, "rebase") && strings.Contains(action, "(start)"):
+		op.Kind = GitRebaseStart
+	case strings.HasPrefix(action, "rebase") && strings.Contains(action, "(finish)"):
+		op.Kind = GitRebaseEnd
+	case strings.HasPrefix(action, "rebase"):
+		op.Kind = GitRebase
+	}
+	return op
+}
@@ -0,0 +1,231 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	oldHash = "1111111111111111111111111111111111111111"
+	newHash = "2222222222222222222222222222222222222222"
+)
+
+func TestParseReflogEntry(t *testing.T) {
+	tests := []struct {
+		message string
+		kind    GitOpKind
+	}{
+		{"commit: Fix parser", GitCommit},
+		{"commit (initial): First commit", GitCommit},
+		{"commit (amend): Fix parser", GitCommit},
+		{"cherry-pick: Fix parser", GitCommit},
+		{"revert: Revert \"Fix parser\"", GitCommit},
+		{"commit (merge): Merge branch 'feature'", GitMerge},
+		{"merge feature: Fast-forward", GitMerge},
+		{"pull: Fast-forward", GitMerge},
+		{"checkout: moving from main to feature", GitCheckout},
+		{"switch: moving from main to feature", GitCheckout},
+		{"reset: moving to HEAD~1", GitReset},
+		{"rebase (start): checkout main", GitRebaseStart},
+		{"rebase (pick): Fix parser", GitRebase},
+		{"rebase (continue): Fix parser", GitRebase},
+		{"rebase (finish): returning to refs/heads/feature", GitRebaseEnd},
+		{"branch: Created from HEAD", GitOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			op := parseReflogEntry(reflogLine(tt.message))
+			if op.Kind != tt.kind {
+				t.Errorf("kind = %v, want %v", op.Kind, tt.kind)
+			}
+			if op.Message != tt.message {
+				t.Errorf("message = %q, want %q", op.Message, tt.message)
+			}
+			if op.Commit != newHash {
+				t.Errorf("commit = %q, want %q", op.Commit, newHash)
+			}
+			if op.Author != "Ada Lovelace" {
+				t.Errorf("author = %q, want %q", op.Author, "Ada Lovelace")
+			}
+		})
+	}
+}
+
+func TestParseReflogEntryMalformed(t *testing.T) {
+	op := parseReflogEntry("")
+	if op.Kind != GitOther || op.Commit != "" || op.Author != "" {
+		t.Errorf("empty line parsed as %+v", op)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, gitDir string) // before the baseline is taken
+		change  func(t *testing.T, gitDir string)
+		kind    GitOpKind
+		message string
+	}{
+		{
+			name:    "commit",
+			change:  func(t *testing.T, d string) { appendLine(t, d, "logs/HEAD", reflogLine("commit: Fix parser")) },
+			kind:    GitCommit,
+			message: "commit: Fix parser",
+		},
+		{
+			name:    "amend",
+			change:  func(t *testing.T, d string) { appendLine(t, d, "logs/HEAD", reflogLine("commit (amend): Fix parser")) },
+			kind:    GitCommit,
+			message: "commit (amend): Fix parser",
+		},
+		{
+			name:    "merge",
+			change:  func(t *testing.T, d string) { appendLine(t, d, "logs/HEAD", reflogLine("merge feature: Fast-forward")) },
+			kind:    GitMerge,
+			message: "merge feature: Fast-forward",
+		},
+		{
+			name:    "merge with conflicts",
+			change:  func(t *testing.T, d string) { writeFile(t, d, "MERGE_HEAD", newHash+"\n") },
+			kind:    GitMerge,
+			message: "merge: stopped with conflicts",
+		},
+		{
+			name: "checkout",
+			change: func(t *testing.T, d string) {
+				writeFile(t, d, "HEAD", "ref: refs/heads/feature\n")
+				appendLine(t, d, "logs/HEAD", reflogLine("checkout: moving from main to feature"))
+			},
+			kind:    GitCheckout,
+			message: "checkout: moving from main to feature",
+		},
+		{
+			name:    "HEAD changed without a reflog entry",
+			change:  func(t *testing.T, d string) { writeFile(t, d, "HEAD", "ref: refs/heads/feature\n") },
+			kind:    GitCheckout,
+			message: "checkout: feature",
+		},
+		{
+			name:    "reset",
+			change:  func(t *testing.T, d string) { appendLine(t, d, "logs/HEAD", reflogLine("reset: moving to HEAD~1")) },
+			kind:    GitReset,
+			message: "reset: moving to HEAD~1",
+		},
+		{
+			name: "rebase start",
+			change: func(t *testing.T, d string) {
+				mkdir(t, d, "rebase-merge")
+				appendLine(t, d, "logs/HEAD", reflogLine("rebase (start): checkout main"))
+			},
+			kind:    GitRebaseStart,
+			message: "rebase (start): checkout main",
+		},
+		{
+			name:    "rebase step",
+			setup:   func(t *testing.T, d string) { mkdir(t, d, "rebase-merge") },
+			change:  func(t *testing.T, d string) { appendLine(t, d, "logs/HEAD", reflogLine("rebase (pick): Fix parser")) },
+			kind:    GitRebase,
+			message: "rebase (pick): Fix parser",
+		},
+		{
+			name:  "rebase end",
+			setup: func(t *testing.T, d string) { mkdir(t, d, "rebase-apply") },
+			change: func(t *testing.T, d string) {
+				os.RemoveAll(filepath.Join(d, "rebase-apply"))
+				appendLine(t, d, "logs/HEAD", reflogLine("rebase (finish): returning to refs/heads/feature"))
+			},
+			kind:    GitRebaseEnd,
+			message: "rebase (finish): returning to refs/heads/feature",
+		},
+		{
+			name: "stash push",
+			change: func(t *testing.T, d string) {
+				appendLine(t, d, "logs/refs/stash", reflogLine("WIP on main: 1111111 Fix parser"))
+				// git stash resets the worktree, which HEAD's reflog records too
+				appendLine(t, d, "logs/HEAD", reflogLine("reset: moving to HEAD"))
+			},
+			kind:    GitStash,
+			message: "WIP on main: 1111111 Fix parser",
+		},
+		{
+			name: "stash pop",
+			setup: func(t *testing.T, d string) {
+				appendLine(t, d, "logs/refs/stash", reflogLine("WIP on main: 1111111 Fix parser"))
+			},
+			change:  func(t *testing.T, d string) { writeFile(t, d, "logs/refs/stash", "") },
+			kind:    GitStash,
+			message: "stash popped or dropped",
+		},
+		{
+			name:   "index only",
+			change: func(t *testing.T, d string) { writeFile(t, d, "index", "DIRC changed") },
+			kind:   GitIndex,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitDir := t.TempDir()
+			writeFile(t, gitDir, "HEAD", "ref: refs/heads/main\n")
+			writeFile(t, gitDir, "index", "DIRC")
+			appendLine(t, gitDir, "logs/HEAD", reflogLine("commit (initial): First commit"))
+			if tt.setup != nil {
+				tt.setup(t, gitDir)
+			}
+
+			tracker := newGitTracker()
+			tracker.track(gitDir, filepath.Dir(gitDir))
+			tt.change(t, gitDir)
+			op := tracker.classify(gitDir)
+			if op.Kind != tt.kind {
+				t.Errorf("kind = %v, want %v", op.Kind, tt.kind)
+			}
+			if op.Message != tt.message {
+				t.Errorf("message = %q, want %q", op.Message, tt.message)
+			}
+
+			// The state is the new baseline, so nothing changed since
+			if again := tracker.classify(gitDir); again.Kind != GitIndex {
+				t.Errorf("second classify = %v, want %v", again.Kind, GitIndex)
+			}
+		})
+	}
+}
+
+func reflogLine(message string) string {
+	return oldHash + " " + newHash + " Ada Lovelace <ada@example.com> 1700000000 +0000\t" + message
+}
+
+func writeFile(t *testing.T, gitDir, name, content string) {
+	t.Helper()
+	path := filepath.Join(gitDir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func appendLine(t *testing.T, gitDir, name, line string) {
+	t.Helper()
+	path := filepath.Join(gitDir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mkdir(t *testing.T, gitDir, name string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(gitDir, name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
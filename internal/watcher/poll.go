@@ -0,0 +1,150 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultPollInterval is how often the polling backend rescans directories.
+const DefaultPollInterval = time.Second
+
+// fileState is what the poller compares between scans.
+type fileState struct {
+	modTime time.Time
+	size    int64
+	isDir   bool
+}
+
+// poller detects changes by rescanning the modification times and sizes of
+// the files in every added directory. It works where native notifications do
+// not arrive, such as NFS, sshfs and container bind mounts.
+type poller struct {
+	interval  time.Duration
+	mu        sync.Mutex
+	dirs      map[string]map[string]fileState
+	events    chan fsnotify.Event
+	errors    chan error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newPoller(interval time.Duration) *poller {
+	p := &poller{
+		interval: interval,
+		dirs:     make(map[string]map[string]fileState),
+		events:   make(chan fsnotify.Event, 64),
+		errors:   make(chan error, 1),
+		done:     make(chan struct{}),
+	}
+	go p.loop()
+	return p
+}
+
+// Add starts polling dir. Its current contents are the baseline, so files that
+// already exist do not produce events.
+func (p *poller) Add(dir string) error {
+	state, err := scanDir(dir)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.dirs[dir] = state
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *poller) Events() <-chan fsnotify.Event { return p.events }
+func (p *poller) Errors() <-chan error          { return p.errors }
+
+func (p *poller) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+	return nil
+}
+
+func (p *poller) loop() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.poll()
+		}
+	}
+}
+
+// poll rescans every directory and emits an event for each difference.
+func (p *poller) poll() {
+	p.mu.Lock()
+	dirs := make([]string, 0, len(p.dirs))
+	for dir := range p.dirs {
+		dirs = append(dirs, dir)
+	}
+	p.mu.Unlock()
+
+	for _, dir := range dirs {
+		cur, err := scanDir(dir)
+		if err != nil {
+			// A removed directory is reported by its parent's scan
+			p.mu.Lock()
+			delete(p.dirs, dir)
+			p.mu.Unlock()
+			continue
+		}
+
+		p.mu.Lock()
+		prev, ok := p.dirs[dir]
+		if ok {
+			p.dirs[dir] = cur
+		}
+		p.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		for name, st := range cur {
+			old, existed := prev[name]
+			switch {
+			case !existed:
+				p.emit(filepath.Join(dir, name), fsnotify.Create)
+			case st.isDir:
+			case !st.modTime.Equal(old.modTime) || st.size != old.size:
+				p.emit(filepath.Join(dir, name), fsnotify.Write)
+			}
+		}
+		for name := range prev {
+			if _, ok := cur[name]; !ok {
+				p.emit(filepath.Join(dir, name), fsnotify.Remove)
+			}
+		}
+	}
+}
+
+func (p *poller) emit(path string, op fsnotify.Op) {
+	select {
+	case p.events <- fsnotify.Event{Name: path, Op: op}:
+	case <-p.done:
+	}
+}
+
+// scanDir records the state of every entry directly inside dir.
+func scanDir(dir string) (map[string]fileState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	state := make(map[string]fileState, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		state[e.Name()] = fileState{modTime: info.ModTime(), size: info.Size(), isDir: e.IsDir()}
+	}
+	return state, nil
+}
@@ -0,0 +1,142 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPollerEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, dir string)
+		want   []string
+	}{
+		{
+			name:   "nothing changed",
+			change: func(t *testing.T, dir string) {},
+		},
+		{
+			name:   "file created",
+			change: func(t *testing.T, dir string) { writeFile(t, dir, "new.go", "package a\n") },
+			want:   []string{"CREATE new.go"},
+		},
+		{
+			name:   "file written",
+			change: func(t *testing.T, dir string) { writeFile(t, dir, "old.go", "package a\n\nfunc A() {}\n") },
+			want:   []string{"WRITE old.go"},
+		},
+		{
+			name: "file touched",
+			change: func(t *testing.T, dir string) {
+				future := time.Now().Add(time.Hour)
+				if err := os.Chtimes(filepath.Join(dir, "old.go"), future, future); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"WRITE old.go"},
+		},
+		{
+			name: "file removed",
+			change: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "old.go")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"REMOVE old.go"},
+		},
+		{
+			name: "directory created and file written inside an existing one",
+			change: func(t *testing.T, dir string) {
+				mkdir(t, dir, "pkg")
+				writeFile(t, dir, "sub/inner.go", "package sub\n")
+			},
+			want: []string{"CREATE pkg", "CREATE sub/inner.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "old.go", "package a\n")
+			mkdir(t, dir, "sub")
+
+			p := newPoller(time.Hour)
+			defer p.Close()
+			for _, d := range []string{dir, filepath.Join(dir, "sub")} {
+				if err := p.Add(d); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tt.change(t, dir)
+			p.poll()
+
+			if got := drainPoller(p, dir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPollerForgetsRemovedDirectories(t *testing.T) {
+	dir := t.TempDir()
+	mkdir(t, dir, "sub")
+	p := newPoller(time.Hour)
+	defer p.Close()
+	p.Add(dir)
+	p.Add(filepath.Join(dir, "sub"))
+
+	os.Remove(filepath.Join(dir, "sub"))
+	p.poll()
+
+	if got := drainPoller(p, dir); !reflect.DeepEqual(got, []string{"REMOVE sub"}) {
+		t.Errorf("events = %q, want the removal reported once by the parent", got)
+	}
+	p.mu.Lock()
+	_, polled := p.dirs[filepath.Join(dir, "sub")]
+	p.mu.Unlock()
+	if polled {
+		t.Error("the removed directory is still polled")
+	}
+}
+
+func TestPollBackendDeliversEvents(t *testing.T) {
+	root := t.TempDir()
+	w, err := New(root, NewFilter(root, nil), Options{Backend: BackendPoll, PollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if got := w.Stats().Backend; got != BackendPoll {
+		t.Fatalf("backend = %q, want %q", got, BackendPoll)
+	}
+
+	path := filepath.Join(root, "main.go")
+	writeFile(t, root, "main.go", "package main\n")
+	select {
+	case ev := <-w.Events():
+		if ev.Path != path || ev.Op != OpCreate {
+			t.Errorf("event = %v %s, want %v %s", ev.Op, ev.Path, OpCreate, path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event from the poll backend")
+	}
+}
+
+// drainPoller returns the queued events as "OP path", with paths relative to
+// dir, sorted.
+func drainPoller(p *poller, dir string) []string {
+	var events []string
+	for {
+		select {
+		case ev := <-p.Events():
+			rel, _ := filepath.Rel(dir, ev.Name)
+			events = append(events, ev.Op.String()+" "+rel)
+		default:
+			sort.Strings(events)
+			return events
+		}
+	}
+}
@@ -1,12 +1,13 @@
 package watcher
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
-	"strings"
+	"sort"
 	"sync"
 	"time"
 
@@ -83,68 +84,238 @@ func initWatcherDebugLogging(logDir string) {
 	debugFile.Sync()
 }
 
+// Options configures how a Watcher receives filesystem events.
+type Options struct {
+	// Backend is BackendAuto (the default), BackendFsnotify or BackendPoll.
+	// Auto uses native notifications and falls back to polling on remote
+	// filesystems or when they cannot start. Once the watch limit is hit, it
+	// polls just the directories that could not be watched.
+	Backend string
+	// PollInterval is how often the polling backend rescans; zero means
+	// DefaultPollInterval.
+	PollInterval time.Duration
+	// DiscoverRepos reports repositories created or deleted under the root
+	// with OpRepoAdd and OpRepoRemove events. Repositories inside a known
+	// one are not reported.
+	DiscoverRepos bool
+}
+
 // Watcher monitors a directory recursively for file changes.
 type Watcher struct {
 	root       string
 	filter     *Filter
-	fsw        *fsnotify.Watcher
-	changes    chan string
-	pending    map[string]struct{}
+	backend    backend
+	kind       string
+	overflow   *poller // polls directories the backend has no watches left for
+	events     chan Event
+	errors     chan error
+	pending    map[string]Event
 	batchTimer *time.Timer
 	pendingMu  sync.Mutex
 	done       chan struct{}
+	onSettle   []func(paths []string)
+	discover   bool
+
+	git *gitTracker
+
+	statsMu      sync.Mutex
+	stats        Stats
+	watchedDirs  map[string]bool
+	limitReached bool
 }
 
 // New creates a recursive file watcher on the given root directory.
-func New(root string, filter *Filter) (*Watcher, error) {
-	fsw, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, err
+func New(root string, filter *Filter, opts Options) (*Watcher, error) {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
 	}
 
 	w := &Watcher{
-		root:    root,
-		filter:  filter,
-		fsw:     fsw,
-		changes: make(chan string, 64),
-		pending: make(map[string]struct{}),
-		done:    make(chan struct{}),
+		root:        root,
+		filter:      filter,
+		events:      make(chan Event, 64),
+		errors:      make(chan error, 16),
+		pending:     make(map[string]Event),
+		done:        make(chan struct{}),
+		git:         newGitTracker(),
+		discover:    opts.DiscoverRepos,
+		watchedDirs: make(map[string]bool),
 	}
 
 	initWatcherDebugLogging(getLogDir())
 
-	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+	if err := w.start(opts); err != nil {
+		return nil, err
+	}
+	logMessage(fmt.Sprintf("Watching %s with the %s backend", root, w.kind))
+
+	go w.loop()
+	return w, nil
+}
+
+// start picks the backend and adds every directory of the tree to it.
+func (w *Watcher) start(opts Options) error {
+	switch opts.Backend {
+	case BackendPoll:
+		return w.startPoller(opts.PollInterval)
+	case BackendFsnotify:
+		b, err := newFsnotifyBackend()
+		if err != nil {
+			return err
+		}
+		w.backend, w.kind = b, BackendFsnotify
+		if err := w.watchTree(w.root, nil); err != nil {
+			b.Close()
+			return err
+		}
+		return nil
+	case BackendAuto, "":
+	default:
+		return fmt.Errorf("unknown watcher backend %q (want %s, %s or %s)", opts.Backend, BackendAuto, BackendFsnotify, BackendPoll)
+	}
+
+	if fsType := remoteFSType(w.root); fsType != "" {
+		logMessage(fmt.Sprintf("%s is on %s, which does not deliver change notifications; polling", w.root, fsType))
+		return w.startPoller(opts.PollInterval)
+	}
+
+	b, err := newFsnotifyBackend()
+	if err != nil {
+		logMessage(fmt.Sprintf("Native notifications unavailable (%v); polling", err))
+		return w.startPoller(opts.PollInterval)
+	}
+	w.backend, w.kind = b, BackendFsnotify
+	w.overflow = newPoller(opts.PollInterval)
+	if err := w.watchTree(w.root, nil); err != nil {
+		b.Close()
+		w.overflow.Close()
+		return err
+	}
+	return nil
+}
+
+func (w *Watcher) startPoller(interval time.Duration) error {
+	p := newPoller(interval)
+	w.backend, w.kind = p, BackendPoll
+	if err := w.watchTree(w.root, nil); err != nil {
+		p.Close()
+		return err
+	}
+	return nil
+}
+
+// watchTree adds every directory under root that is not ignored. Directories
+// that cannot be added are skipped; addDir reports why. onFile, if not nil, is
+// called for every file found.
+func (w *Watcher) watchTree(root string, onFile func(path string)) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
-		if d.IsDir() {
-			if filter.ShouldIgnore(path) {
+		if d.Name() == ".git" {
+			w.watchGitDir(path)
+			if d.IsDir() {
 				return filepath.SkipDir
 			}
-			if addErr := fsw.Add(path); addErr != nil {
-				return nil
+			return nil
+		}
+		if !d.IsDir() {
+			if onFile != nil {
+				onFile(path)
 			}
+			return nil
 		}
+		if w.filter.ShouldIgnore(path) {
+			return filepath.SkipDir
+		}
+		w.addDir(path)
 		return nil
 	})
-	if err != nil {
-		fsw.Close()
-		return nil, err
+}
+
+// watchGitDir starts tracking the repository, worktree or submodule a .git
+// entry belongs to. Its git directory, which for worktrees and submodules is
+// elsewhere, is watched along with the reflog directory, so that every HEAD
+// movement is noticed.
+func (w *Watcher) watchGitDir(dotGit string) {
+	gitDir := resolveGitDir(dotGit)
+	if gitDir == "" {
+		return
 	}
+	if err := w.addDir(gitDir); err != nil {
+		return
+	}
+	w.addDir(filepath.Join(gitDir, "logs"))
+	root := filepath.Dir(dotGit)
+	w.git.track(gitDir, root)
 
-	go w.loop()
-	return w, nil
+	if !w.discover || w.filter.isRepoRoot(root) || w.filter.repoRoot(root) != "" {
+		return
+	}
+	logMessage(fmt.Sprintf("Discovered repository %s", root))
+	w.filter.AddRepoRoot(root)
+	w.queue("repo:"+root, Event{Path: root, Op: OpRepoAdd, Time: time.Now(), Repo: filepath.Base(root)})
+}
+
+// forgetRepo reports a deleted repository, given its root or .git directory.
+// It reports whether path was one.
+func (w *Watcher) forgetRepo(path string) bool {
+	root := path
+	if filepath.Base(path) == ".git" {
+		root = filepath.Dir(path)
+	}
+	if !w.discover || !w.filter.isRepoRoot(root) {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(root, ".git")); err == nil {
+		return false
+	}
+	logMessage(fmt.Sprintf("Repository %s was removed", root))
+	w.filter.RemoveRepoRoot(root)
+	w.git.forget(root)
+	w.queue("repo:"+root, Event{Path: root, Op: OpRepoRemove, Time: time.Now(), Repo: filepath.Base(root)})
+	return true
+}
+
+// Backend returns the name of the backend in use: BackendFsnotify or BackendPoll.
+func (w *Watcher) Backend() string {
+	return w.kind
+}
+
+// Events returns a read-only channel that emits batched change events.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Errors returns a channel reporting problems that may cause missed changes,
+// such as directories that cannot be watched or ErrOverflow.
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
 }
 
-// Changes returns a read-only channel that emits changed file paths.
-func (w *Watcher) Changes() <-chan string {
-	return w.changes
+// Stats reports the backend in use and how much the watcher has seen.
+func (w *Watcher) Stats() Stats {
+	w.statsMu.Lock()
+	defer w.statsMu.Unlock()
+	st := w.stats
+	st.Backend = w.kind
+	return st
+}
+
+// OnSettle registers a callback invoked with the changed file paths of every
+// batch once it has been delivered on the Events channel. Callbacks must be
+// registered before changes arrive.
+func (w *Watcher) OnSettle(fn func(paths []string)) {
+	w.onSettle = append(w.onSettle, fn)
 }
 
 // Close stops the watcher and releases resources.
 func (w *Watcher) Close() error {
 	close(w.done)
-	return w.fsw.Close()
+	if w.overflow != nil {
+		w.overflow.Close()
+	}
+	return w.backend.Close()
 }
 
 func (w *Watcher) scheduleBatch() {
@@ -159,23 +330,45 @@ func (w *Watcher) scheduleBatch() {
 				return
 			}
 
-			paths := make([]string, 0, len(w.pending))
-			for path := range w.pending {
-				paths = append(paths, path)
+			batch := make([]Event, 0, len(w.pending))
+			for _, ev := range w.pending {
+				batch = append(batch, ev)
 			}
-			w.pending = make(map[string]struct{})
+			w.pending = make(map[string]Event)
 			w.pendingMu.Unlock()
 
-			logMessage(fmt.Sprintf("Processing batch of %d changes", len(paths)))
-			for _, path := range paths {
-				select {
-				case w.changes <- path:
-					if path == "__GIT_OPERATION__" {
-						logMessage("Sent git operation marker to channel")
+			// New repositories first, so their files can be diffed
+			sort.SliceStable(batch, func(i, j int) bool {
+				return batch[i].Op == OpRepoAdd && batch[j].Op != OpRepoAdd
+			})
+
+			logMessage(fmt.Sprintf("Processing batch of %d changes", len(batch)))
+			var paths []string
+			for _, ev := range batch {
+				if ev.Op == OpGit {
+					gitDir := stateGitDir(ev.Path)
+					ev.Git = w.git.classify(gitDir)
+					if root, ok := w.git.rootOf(gitDir); ok {
+						_, ev.Git.Submodule = w.gitRepo(root)
 					}
+					logMessage(fmt.Sprintf("Git operation in %q: %s %q", ev.Repo, ev.Git.Kind, ev.Git.Message))
+				}
+				select {
+				case w.events <- ev:
 				case <-w.done:
 					return
 				}
+				w.statsMu.Lock()
+				w.stats.Events++
+				w.stats.LastEvent = ev.Time
+				w.statsMu.Unlock()
+				if ev.Op.IsFile() {
+					paths = append(paths, ev.Path)
+				}
+			}
+
+			for _, fn := range w.onSettle {
+				fn(paths)
 			}
 		})
 	} else {
@@ -184,26 +377,69 @@ func (w *Watcher) scheduleBatch() {
 }
 
 func (w *Watcher) loop() {
+	var overflowEvents <-chan fsnotify.Event
+	if w.overflow != nil {
+		overflowEvents = w.overflow.Events()
+	}
 	for {
 		select {
 		case <-w.done:
 			return
-		case event, ok := <-w.fsw.Events:
+		case event, ok := <-w.backend.Events():
 			if !ok {
 				return
 			}
 			w.handleEvent(event)
-		case _, ok := <-w.fsw.Errors:
+		case event := <-overflowEvents:
+			w.handleEvent(event)
+		case err, ok := <-w.backend.Errors():
 			if !ok {
 				return
 			}
+			logMessage(fmt.Sprintf("Backend error: %v", err))
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				err = ErrOverflow
+			}
+			w.reportError(err)
 		}
 	}
 }
 
+// reportError passes err on to the Errors channel, dropping it when nobody is
+// keeping up with the channel.
+func (w *Watcher) reportError(err error) {
+	select {
+	case w.errors <- err:
+	default:
+	}
+}
+
 func (w *Watcher) handleEvent(event fsnotify.Event) {
 	path := event.Name
 
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		if w.forgetRepo(path) {
+			return
+		}
+	}
+
+	if gitDir := stateGitDir(path); gitDir != "" {
+		if root, ok := w.git.rootOf(gitDir); ok {
+			repo, _ := w.gitRepo(root)
+			logMessage(fmt.Sprintf("Detected git operation (%s changed) in %q", filepath.Base(path), repo))
+			// One event per repo and batch, however many state files changed
+			w.queue("git:"+gitDir, Event{Path: path, Op: OpGit, Time: time.Now(), Repo: repo})
+			return
+		}
+	}
+	if filepath.Base(path) == ".git" {
+		// A repository, worktree or submodule appeared
+		if event.Has(fsnotify.Create) {
+			w.watchGitDir(path)
+		}
+		return
+	}
+
 	if w.filter.ShouldIgnore(path) {
 		return
 	}
@@ -211,28 +447,133 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 	if event.Has(fsnotify.Create) {
 		info, err := os.Stat(path)
 		if err == nil && info.IsDir() {
-			w.fsw.Add(path)
+			w.watchNewTree(path)
 			return
 		}
 	}
 
-	if strings.Contains(path, ".git") && (filepath.Base(path) == "HEAD" || filepath.Base(path) == "index") {
-		logMessage(fmt.Sprintf("Detected git operation (%s changed), triggering full refresh", filepath.Base(path)))
-		w.pendingMu.Lock()
-		w.pending["__GIT_OPERATION__"] = struct{}{}
-		w.pendingMu.Unlock()
-		w.scheduleBatch()
+	ev := Event{
+		Path: path,
+		Time: time.Now(),
+		Repo: w.repoName(path),
+	}
+	if ev.Repo == "" && w.discover {
+		// Outside every repository, such as a repo's root once it is deleted
 		return
 	}
-
-	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) &&
-		!event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+	switch {
+	case event.Has(fsnotify.Create):
+		ev.Op = OpCreate
+	case event.Has(fsnotify.Write):
+		ev.Op = OpWrite
+	case event.Has(fsnotify.Remove):
+		ev.Op = OpRemove
+	case event.Has(fsnotify.Rename):
+		ev.Op = OpRename
+	default:
 		return
 	}
 
+	w.queue(path, ev)
+}
+
+// queue adds an event to the pending batch under key, so that one batch holds
+// at most one event per key.
+func (w *Watcher) queue(key string, ev Event) {
 	w.pendingMu.Lock()
-	w.pending[path] = struct{}{}
+	// A file created and then written within one batch is still new
+	if prev, ok := w.pending[key]; !ok || prev.Op != OpCreate || ev.Op != OpWrite {
+		w.pending[key] = ev
+	}
 	w.pendingMu.Unlock()
 
 	w.scheduleBatch()
 }
+
+// watchNewTree watches a directory created after startup and everything in
+// it. Files and subdirectories created before its watch was in place, as with
+// mkdir -p followed by a quick write or an extracted archive, would otherwise
+// go unnoticed, so the files found are reported as created.
+func (w *Watcher) watchNewTree(dir string) {
+	logMessage(fmt.Sprintf("New directory %s, watching its tree", dir))
+	w.watchTree(dir, func(path string) {
+		w.handleEvent(fsnotify.Event{Name: path, Op: fsnotify.Create})
+	})
+}
+
+// gitRepo returns the name of the repository whose git operations happen in
+// the working tree at root and, when root is a submodule of it, the
+// submodule's path.
+func (w *Watcher) gitRepo(root string) (repo, submodule string) {
+	if w.filter.isRepoRoot(root) {
+		return filepath.Base(root), ""
+	}
+	parent := w.filter.repoRoot(root)
+	if parent == "" {
+		return filepath.Base(root), ""
+	}
+	rel, _ := filepath.Rel(parent, root)
+	return filepath.Base(parent), rel
+}
+
+// repoName returns the name of the repo containing path, like the differ
+// names them, or "" when path is outside every repo.
+func (w *Watcher) repoName(path string) string {
+	if root := w.filter.repoRoot(path); root != "" {
+		return filepath.Base(root)
+	}
+	return ""
+}
+
+// addDir adds a directory to the backend, counting it in the stats. When the
+// backend is out of watches, the directory is polled instead.
+func (w *Watcher) addDir(dir string) error {
+	err := w.backend.Add(dir)
+	if err != nil && w.overflow != nil && isWatchLimit(err) {
+		return w.pollDir(dir, err)
+	}
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logMessage(fmt.Sprintf("Cannot watch %s: %v", dir, err))
+			w.reportError(fmt.Errorf("cannot watch %s: %w", dir, err))
+		}
+		return err
+	}
+	w.statsMu.Lock()
+	w.countWatched(dir)
+	w.statsMu.Unlock()
+	return nil
+}
+
+// countWatched counts dir in the stats unless it already is: a directory
+// created after startup is added both for its own create event and by the
+// walk of its parent's new tree. It reports whether dir was new. The caller
+// holds statsMu.
+func (w *Watcher) countWatched(dir string) bool {
+	if w.watchedDirs[dir] {
+		return false
+	}
+	w.watchedDirs[dir] = true
+	w.stats.Watched++
+	return true
+}
+
+// pollDir polls a directory that could not be watched because of limitErr,
+// reporting the first time the limit is reached.
+func (w *Watcher) pollDir(dir string, limitErr error) error {
+	if err := w.overflow.Add(dir); err != nil {
+		return err
+	}
+	w.statsMu.Lock()
+	if w.countWatched(dir) {
+		w.stats.Polled++
+	}
+	first := !w.limitReached
+	w.limitReached = true
+	w.statsMu.Unlock()
+	if first {
+		logMessage(fmt.Sprintf("Watch limit reached at %s (%v); polling the rest", dir, limitErr))
+		w.reportError(fmt.Errorf("%w (%v); polling directories beyond it", ErrWatchLimit, limitErr))
+	}
+	return nil
+}
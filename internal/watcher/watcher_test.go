@@ -0,0 +1,35 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestNewTreeCountsDirectoriesOnce(t *testing.T) {
+	root := t.TempDir()
+	// The poller never rescans within the test, so only the events sent
+	// below arrive
+	w, err := New(root, NewFilter(root, nil), Options{Backend: BackendPoll, PollInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	nested := filepath.Join(root, "a", "b", "c")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// The create events of a and of b, which the walk of a's tree already
+	// added
+	w.handleEvent(fsnotify.Event{Name: filepath.Join(root, "a"), Op: fsnotify.Create})
+	w.handleEvent(fsnotify.Event{Name: filepath.Join(root, "a", "b"), Op: fsnotify.Create})
+	w.handleEvent(fsnotify.Event{Name: nested, Op: fsnotify.Create})
+
+	if got := w.Stats().Watched; got != 4 {
+		t.Errorf("Watched = %d, want 4 (root, a, a/b and a/b/c)", got)
+	}
+}
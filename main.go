@@ -13,8 +13,11 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"codeberg.org/devcarlosmolero/vibewatch/internal/config"
 	"codeberg.org/devcarlosmolero/vibewatch/internal/differ"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/hooks"
 	"codeberg.org/devcarlosmolero/vibewatch/internal/model"
+	"codeberg.org/devcarlosmolero/vibewatch/internal/theme"
 	"codeberg.org/devcarlosmolero/vibewatch/internal/watcher"
 )
 
@@ -25,6 +28,13 @@ func main() {
 	dir := flag.String("dir", ".", "directory to watch (git repo or parent of multiple repos)")
 	repoFilter := flag.String("repos", "", "comma-separated list of repo names to watch (only applies in multi-repo mode)")
 	maxEntries := flag.Int("max", 200, "maximum number of diff entries to keep")
+	configPath := flag.String("config", config.DefaultPath(), "path to the JSON config file")
+	contextLines := flag.Int("context", differ.DefaultContext, "number of context lines around changes (overrides context in the config)")
+	themeName := flag.String("theme", "", "color theme: auto, dark, light, high-contrast, colorblind or a theme from the config (overrides theme in the config)")
+	watcherBackend := flag.String("watcher", watcher.BackendAuto, "how to detect changes: auto, fsnotify or poll (overrides watcher in the config)")
+	pollInterval := flag.Duration("poll-interval", watcher.DefaultPollInterval, "how often the poll watcher rescans (overrides poll_interval in the config)")
+	feedbackFile := flag.String("feedback", "", "file that review comments are written to (default vibewatch/feedback.md in the repo's git directory)")
+	baseRef := flag.String("base", "", "git ref to diff the working tree against, such as main, a tag or HEAD~3 (default: the index)")
 	flag.Parse()
 
 	if *versionFlag {
@@ -32,6 +42,41 @@ func main() {
 		os.Exit(0)
 	}
 
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	keys, err := model.NewKeyMap(cfg.Keys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "context":
+			cfg.Context = *contextLines
+		case "theme":
+			cfg.Theme = *themeName
+		case "watcher":
+			cfg.Watcher = *watcherBackend
+		case "poll-interval":
+			cfg.PollIntervalPeriod = *pollInterval
+		}
+	})
+	if cfg.Context < 0 {
+		fmt.Fprintf(os.Stderr, "Error: context must not be negative, got %d\n", cfg.Context)
+		os.Exit(1)
+	}
+
+	t, err := theme.Resolve(cfg.Theme, cfg.Themes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	model.ApplyTheme(t)
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -60,6 +105,7 @@ func main() {
 	var repoNames []string
 	var branches map[string]string
 	var singleBranch string
+	discoverRepos := false
 
 	if differ.IsGitRepo(absDir) {
 		gd, err := differ.NewGit(absDir)
@@ -67,6 +113,7 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		gd.SetContext(cfg.Context)
 		d = gd
 		modeLabel = absDir
 		repoRoots = []string{absDir}
@@ -114,8 +161,9 @@ func main() {
 				os.Exit(1)
 			}
 		} else {
-			// No filter provided, use all repos
+			// No filter provided, use all repos, including ones created later
 			repos = allRepos
+			discoverRepos = true
 		}
 
 		md, err := differ.NewMulti(repos)
@@ -123,6 +171,7 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		md.SetContext(cfg.Context)
 		d = md
 		repoRoots = md.RepoRoots()
 
@@ -140,15 +189,56 @@ func main() {
 		}
 	}
 
+	if *baseRef != "" {
+		if err := d.SetBase(*baseRef); err != nil {
+			if d.Base() == "" {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if *feedbackFile != "" {
+		cfg.FeedbackFile = *feedbackFile
+	}
+	if cfg.FeedbackFile == "" {
+		cfg.FeedbackFile = defaultFeedbackFile(absDir)
+	}
+	if abs, err := filepath.Abs(cfg.FeedbackFile); err == nil {
+		cfg.FeedbackFile = abs
+	}
+
 	filter := watcher.NewFilter(absDir, repoRoots)
-	w, err := watcher.New(absDir, filter)
+	filter.IgnorePath(cfg.FeedbackFile)
+	d.IgnorePath(cfg.FeedbackFile)
+	w, err := watcher.New(absDir, filter, watcher.Options{
+		Backend:       cfg.Watcher,
+		PollInterval:  cfg.PollIntervalPeriod,
+		DiscoverRepos: discoverRepos,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error starting watcher: %v\n", err)
 		os.Exit(1)
 	}
 	defer w.Close()
 
-	m := model.New(w.Changes(), d, *maxEntries, modeLabel, repoNames, branches, singleBranch)
+	var hookResults, checkResults <-chan hooks.Result
+	if len(cfg.Hooks) > 0 {
+		runner := hooks.New(absDir, cfg.Hooks)
+		defer runner.Close()
+		w.OnSettle(runner.Run)
+		hookResults = runner.Results()
+	}
+	if len(cfg.Checks) > 0 {
+		checks := hooks.New(absDir, cfg.Checks)
+		defer checks.Close()
+		w.OnSettle(checks.Debounced(cfg.CheckQuietPeriod))
+		checkResults = checks.Results()
+		checks.RunAll()
+	}
+
+	m := model.New(w, d, *maxEntries, modeLabel, repoNames, branches, singleBranch, hookResults, checkResults, keys, cfg)
 	p := tea.NewProgram(&m, tea.WithAltScreen(), tea.WithMouseAllMotion(), tea.WithContext(ctx))
 	if _, err := p.Run(); err != nil {
 		if err != context.Canceled {
@@ -157,3 +247,13 @@ func main() {
 		}
 	}
 }
+
+// defaultFeedbackFile keeps review comments out of the working tree: in the
+// git directory of a single repo, or beside the repos in multi-repo mode,
+// where no repository sees it.
+func defaultFeedbackFile(dir string) string {
+	if gitDir := differ.GitDir(dir); gitDir != "" {
+		return filepath.Join(gitDir, "vibewatch", "feedback.md")
+	}
+	return filepath.Join(dir, ".vibewatch", "feedback.md")
+}